@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"finalProject/pkg/api"
 	"finalProject/pkg/db"
@@ -22,7 +23,46 @@ func main() {
 			log.Printf("error when closing DB: %v", err)
 		}
 	}()
-	api.Init()
+
+	if calendarFile := os.Getenv("TODO_CALENDAR"); calendarFile != "" {
+		count, err := db.LoadCalendar(calendarFile)
+		if err != nil {
+			log.Fatalf("calendar loading error %v", err)
+		}
+		log.Printf("Loaded %d calendar days from %s", count, calendarFile)
+	}
+
+	if tz := os.Getenv("TODO_TZ"); tz != "" {
+		if err := api.SetTimeZone(tz); err != nil {
+			log.Fatalf("time zone error %v", err)
+		}
+		log.Printf("Time zone is %s", tz)
+	}
+
+	if days := os.Getenv("TODO_TRASH_DAYS"); days != "" {
+		if err := api.SetTrashPeriod(days); err != nil {
+			log.Fatalf("trash period error %v", err)
+		}
+		log.Printf("Deleted tasks are kept for %s days", days)
+	}
+
+	if os.Getenv("TODO_DEBUG") != "" {
+		api.EnableDebug()
+		log.Println("Debug mode is on")
+
+		if asOf := os.Getenv("TODO_AS_OF"); asOf != "" {
+			clock, err := api.ClockAsOf(asOf)
+			if err != nil {
+				log.Fatalf("clock error %v", err)
+			}
+			api.SetClock(clock)
+			log.Printf("Running as of %s", asOf)
+		}
+	}
+
+	if err := api.Init(); err != nil {
+		log.Fatalf("api init error %v", err)
+	}
 
 	log.Println("Starting server")
 	if err := server.StartServ(); err != nil {
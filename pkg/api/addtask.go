@@ -2,7 +2,9 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
@@ -11,6 +13,22 @@ import (
 	"finalProject/pkg/db"
 )
 
+// Repeat modes: the next date of a repeating task is counted from its
+// scheduled date or from the day it is done.
+const (
+	repeatFromSchedule = "schedule"
+	repeatFromDone     = "done"
+)
+
+// longest duration of a task in minutes
+const maxDuration = 24 * 60
+
+// priorities of tasks from none to high
+const (
+	priorityNone = 0
+	priorityHigh = 3
+)
+
 func writeJson(w http.ResponseWriter, data any) {
 	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
 	enc := json.NewEncoder(w)
@@ -20,8 +38,10 @@ func writeJson(w http.ResponseWriter, data any) {
 	}
 }
 
-func dataCheck(task *db.Task) error {
-	now := time.Now()
+// dataCheck validates the task and moves a date that has passed to today,
+// where today is the date of now.
+func dataCheck(task *db.Task, now time.Time) error {
+	today := calendarDay(now)
 
 	if task.Date == "" {
 		task.Date = now.Format(formatDate)
@@ -33,29 +53,140 @@ func dataCheck(task *db.Task) error {
 		return fmt.Errorf("error in date: %w", err)
 	}
 
+	if task.Repeat == "" && (task.RepeatUntil != "" || task.RepeatCount != 0) {
+		log.Println("repeat end without repeat")
+		return fmt.Errorf("repeat end requires a repeat rule")
+	}
+	if task.RepeatUntil != "" {
+		if _, err := time.Parse(formatDate, task.RepeatUntil); err != nil {
+			log.Println("wrong repeat until format")
+			return fmt.Errorf("error in repeat until: %w", err)
+		}
+		if task.Date > task.RepeatUntil {
+			log.Println("date after repeat until")
+			return fmt.Errorf("date cannot be after repeat until")
+		}
+	}
+	if task.RepeatCount < 0 {
+		log.Println("wrong repeat count")
+		return fmt.Errorf("repeat count cannot be negative")
+	}
+	if task.RepeatFrom != "" && task.RepeatFrom != repeatFromSchedule && task.RepeatFrom != repeatFromDone {
+		log.Println("wrong repeat from")
+		return fmt.Errorf("repeat from should be %q or %q", repeatFromSchedule, repeatFromDone)
+	}
+	if task.Repeat == "" && task.RepeatFrom == repeatFromDone {
+		log.Println("repeat from without repeat")
+		return fmt.Errorf("repeat from requires a repeat rule")
+	}
+	if task.Time != "" {
+		taskTime, err := time.Parse(formatTime, task.Time)
+		if err != nil {
+			log.Println("wrong time format")
+			return fmt.Errorf("error in time: %w", err)
+		}
+		task.Time = taskTime.Format(formatTime)
+	}
+	if task.Duration < 0 || task.Duration > maxDuration {
+		log.Println("wrong duration")
+		return fmt.Errorf("duration should be from 0 to %d minutes", maxDuration)
+	}
+	if task.Priority < priorityNone || task.Priority > priorityHigh {
+		log.Println("wrong priority")
+		return fmt.Errorf("priority should be from %d to %d", priorityNone, priorityHigh)
+	}
+	task.Tags, err = normalizeTags(task.Tags)
+	if err != nil {
+		log.Println("wrong tags")
+		return fmt.Errorf("error in tags: %w", err)
+	}
+	if task.ProjectID != "" {
+		if _, err := db.GetProject(task.ProjectID); err != nil {
+			log.Println("wrong project")
+			return fmt.Errorf("error in project: %w", err)
+		}
+	}
+
 	if task.Repeat != "" {
-		next, err := NextDate(now, task.Date, task.Repeat)
+		rule, err := ParseRepeat(task.Repeat)
 		if err != nil {
 			log.Println("wrong repeat")
 			return fmt.Errorf("error in repeat: %w", err)
 		}
+		rule = rule.withLeapDate(t)
+		task.Repeat = rule.String()
+		if task.RepeatCount == 0 {
+			task.RepeatCount = rule.Count
+		}
+
+		if rule.Kind == RuleHourly {
+			return hourlyCheck(task, rule, now)
+		}
 
-		if !t.After(now) {
-			task.Date = next
-		} else {
-			if t.Before(now.Truncate(24 * time.Hour)) {
-				task.Date = now.Format(formatDate)
+		next, _, err := nextOccurrence(rule, now, t)
+		if !t.Before(today) {
+			// the start date is still ahead, the rule may end with it
+			if err != nil && !errors.Is(err, ErrRuleEnded) {
+				log.Println("wrong repeat")
+				return fmt.Errorf("error in repeat: %w", err)
 			}
 			return nil
 		}
+		if err == nil && task.RepeatUntil != "" && next.Format(formatDate) > task.RepeatUntil {
+			err = ErrRuleEnded
+		}
+		if errors.Is(err, ErrRuleEnded) {
+			log.Println("repeat has ended")
+			return fmt.Errorf("repeat rule has no dates after today")
+		}
+		if err != nil {
+			log.Println("wrong repeat")
+			return fmt.Errorf("error in repeat: %w", err)
+		}
+		task.Date = next.Format(formatDate)
+		return nil
 	}
 
-	if !t.After(now) {
+	if !t.After(today) {
 		task.Date = now.Format(formatDate)
 	}
 	return nil
 }
 
+// hourlyCheck moves a task with an hourly rule that is already due to the
+// first occurrence after now.
+func hourlyCheck(task *db.Task, rule Rule, now time.Time) error {
+	if task.Time == "" {
+		log.Println("hourly repeat without time")
+		return fmt.Errorf("hourly repeat requires time")
+	}
+
+	start, err := time.Parse(formatDate+formatTime, task.Date+task.Time)
+	if err != nil {
+		log.Println("wrong data format")
+		return fmt.Errorf("error in date: %w", err)
+	}
+	if start.After(wallClock(now)) {
+		return nil
+	}
+
+	next, _, err := nextOccurrence(rule, now, start)
+	if err == nil && task.RepeatUntil != "" && next.Format(formatDate) > task.RepeatUntil {
+		err = ErrRuleEnded
+	}
+	if errors.Is(err, ErrRuleEnded) {
+		log.Println("repeat has ended")
+		return fmt.Errorf("repeat rule has no dates after today")
+	}
+	if err != nil {
+		log.Println("wrong repeat")
+		return fmt.Errorf("error in repeat: %w", err)
+	}
+	task.Date = next.Format(formatDate)
+	task.Time = next.Format(formatTime)
+	return nil
+}
+
 func TaskHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
@@ -90,7 +221,15 @@ func AddTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = dataCheck(&task)
+	now, err := requestNow(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("current time error:", err)
+		writeJson(w, map[string]string{"error": "current time error"})
+		return
+	}
+
+	err = dataCheck(&task, now)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		log.Println("data check error:", err)
@@ -110,10 +249,46 @@ func AddTaskHandler(w http.ResponseWriter, r *http.Request) {
 	writeJson(w, map[string]any{"id": id})
 }
 
+// UpdateTaskHandler saves the fields given in the request over the stored
+// task, so that clients which send only some of them keep the others.
 func UpdateTaskHandler(w http.ResponseWriter, r *http.Request) {
-	var task db.Task
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("reading body error:", err)
+		writeJson(w, map[string]string{"error": "reading body error"})
+		return
+	}
 
-	err := json.NewDecoder(r.Body).Decode(&task)
+	var req struct {
+		ID string `json:"id"`
+	}
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	id, err := strconv.Atoi(req.ID)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("incorrect id:", err)
+		writeJson(w, map[string]string{"error": "incorrect id"})
+		return
+	}
+
+	stored, err := db.GetTask(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("task not found:", err)
+		writeJson(w, map[string]string{"error": "task not found"})
+		return
+	}
+
+	task := *stored
+	err = json.Unmarshal(body, &task)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		log.Println("deserializing JSON error:", err)
@@ -135,7 +310,15 @@ func UpdateTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = dataCheck(&task)
+	now, err := requestNow(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("current time error:", err)
+		writeJson(w, map[string]string{"error": "current time error"})
+		return
+	}
+
+	err = dataCheck(&task, now)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		log.Println("data check error:", err)
@@ -184,10 +367,23 @@ func GetTaskHandlerId(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	items, err := db.ChecklistItems(idString)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting checklist error:", err)
+		writeJson(w, map[string]string{"error": "getting checklist error"})
+		return
+	}
+	if len(items) > 0 {
+		task.Checklist = items
+		task.Progress = checklistProgress(items)
+	}
+
 	w.WriteHeader(http.StatusOK)
 	writeJson(w, task)
 }
 
+// DeleteTaskHandler moves the task to the trash.
 func DeleteTaskHandler(w http.ResponseWriter, r *http.Request) {
 
 	idString := r.URL.Query().Get("id")
@@ -198,7 +394,21 @@ func DeleteTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := db.DeleteTask(idString)
+	now, err := requestNow(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("current time error:", err)
+		writeJson(w, map[string]string{"error": "current time error"})
+		return
+	}
+
+	err = db.TrashTask(idString, storedStamp(now))
+	if errors.Is(err, db.ErrTaskNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("delete task error:", err)
+		writeJson(w, map[string]string{"error": "task not found"})
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println("delete task error:", err)
@@ -206,6 +416,8 @@ func DeleteTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	purgeExpired(now)
+
 	writeJson(w, map[string]any{})
 }
 
@@ -240,32 +452,91 @@ func DoneTaskHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if task.Repeat == "" {
-		err = db.DeleteTask(idString)
-		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			log.Println("delete task error:", err)
-			writeJson(w, map[string]string{"error": "delete task error"})
-		}
-		writeJson(w, map[string]any{})
+	if task.Blocked {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("task is blocked:", idString)
+		writeJson(w, map[string]string{"error": "task is blocked by unfinished tasks"})
 		return
 	}
 
-	nextDay := time.Now().AddDate(0, 0, 1)
-
-	nextDate, err := NextDate(nextDay, task.Date, task.Repeat)
+	now, err := requestNow(r)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		log.Println("error NextDate:", err)
-		writeJson(w, map[string]string{"error": "error NextDate"})
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("current time error:", err)
+		writeJson(w, map[string]string{"error": "current time error"})
 		return
 	}
 
-	err = db.UpdateDate(nextDate, idString)
+	// the task as it is now goes to the history, the occurrence is rescheduled
+	// below
+	done := historyEntry(task, now)
+
+	last := task.Repeat == "" || task.RepeatCount == 1
+
+	var next, original time.Time
+	var hourly bool
+	if !last {
+		exceptions, err := db.Exceptions(idString)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("getting exceptions error:", err)
+			writeJson(w, map[string]string{"error": "getting exceptions error"})
+			return
+		}
+
+		rule, err := ParseRepeat(task.Repeat)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("error NextDate:", err)
+			writeJson(w, map[string]string{"error": "error NextDate"})
+			return
+		}
+
+		hourly = rule.Kind == RuleHourly
+
+		from := now
+		if task.RepeatFrom == repeatFromDone {
+			// the series starts over from the moment the task is done
+			task.Date = now.Format(formatDate)
+			if hourly {
+				task.Time = now.Format(formatTime)
+			}
+		} else if !hourly {
+			from = now.AddDate(0, 0, 1)
+		}
+
+		next, original, err = nextTaskOccurrence(from, task, exceptions)
+		if errors.Is(err, ErrRuleEnded) {
+			last = true
+		} else if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("error NextDate:", err)
+			writeJson(w, map[string]string{"error": "error NextDate"})
+			return
+		} else if task.RepeatUntil != "" && original.Format(formatDate) > task.RepeatUntil {
+			last = true
+		}
+	}
+
+	completion := &db.Completion{
+		TaskID:  idString,
+		History: done,
+		Last:    last,
+	}
+	if !last {
+		completion.Next = next.Format(formatDate)
+		completion.NextTime = task.Time
+		if hourly {
+			completion.NextTime = next.Format(formatTime)
+		}
+		completion.Original = original.Format(formatDate)
+	}
+
+	err = db.CompleteTask(completion)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
-		log.Println("update data error:", err)
-		writeJson(w, map[string]string{"error": "update data error"})
+		log.Println("complete task error:", err)
+		writeJson(w, map[string]string{"error": "complete task error"})
 		return
 	}
 
@@ -1,12 +1,36 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 )
 
-func Init() {
+func Init() error {
+	if err := loadCalendar(); err != nil {
+		return fmt.Errorf("loading calendar error: %w", err)
+	}
+
 	http.HandleFunc("/api/nextdate", NextDateHandler)
+	http.HandleFunc("/api/occurrences", OccurrencesHandler)
+	http.HandleFunc("/api/describe", DescribeHandler)
+	http.HandleFunc("/api/parse", ParseNaturalHandler)
 	http.HandleFunc("/api/task", TaskHandler)
 	http.HandleFunc("/api/tasks", GetTasksHandler)
 	http.HandleFunc("/api/task/done", DoneTaskHandler)
+	http.HandleFunc("/api/task/exceptions", ExceptionsHandler)
+	http.HandleFunc("/api/task/checklist", ChecklistHandler)
+	http.HandleFunc("/api/task/checklist/check", CheckItemHandler)
+	http.HandleFunc("/api/task/checklist/uncheck", UncheckItemHandler)
+	http.HandleFunc("/api/task/checklist/reorder", ReorderChecklistHandler)
+	http.HandleFunc("/api/task/dependencies", DependenciesHandler)
+	http.HandleFunc("/api/history", HistoryHandler)
+	http.HandleFunc("/api/trash", TrashHandler)
+	http.HandleFunc("/api/trash/restore", RestoreTaskHandler)
+	http.HandleFunc("/api/calendar", CalendarHandler)
+	http.HandleFunc("/api/project", ProjectHandler)
+	http.HandleFunc("/api/projects", GetProjectsHandler)
+	http.HandleFunc("/api/tags", TagsHandler)
+	http.HandleFunc("/api/tags/merge", MergeTagsHandler)
+
+	return nil
 }
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"finalProject/pkg/db"
+)
+
+// modifier that moves occurrences from days off to the next working day
+const shiftModifier = "shift"
+
+// calendar holds the days that differ from the Monday-to-Friday working week:
+// holidays and working weekend days.
+var calendar = struct {
+	sync.RWMutex
+	working map[string]bool
+}{working: map[string]bool{}}
+
+type CalendarResp struct {
+	Days []*db.CalendarDay `json:"days"`
+}
+
+func loadCalendar() error {
+	days, err := db.CalendarDays()
+	if err != nil {
+		return err
+	}
+
+	working := make(map[string]bool, len(days))
+	for _, day := range days {
+		working[day.Date] = day.Working
+	}
+
+	calendar.Lock()
+	calendar.working = working
+	calendar.Unlock()
+	return nil
+}
+
+func isWorkday(date time.Time) bool {
+	calendar.RLock()
+	working, ok := calendar.working[date.Format(formatDate)]
+	calendar.RUnlock()
+	if ok {
+		return working
+	}
+	return isWeekday(date)
+}
+
+// isWeekday reports whether the date is from Monday to Friday.
+func isWeekday(date time.Time) bool {
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+// nextWorkday returns the date itself when it is a working day and the
+// following working day otherwise.
+func nextWorkday(date time.Time) time.Time {
+	limit := date.AddDate(1, 0, 0)
+	for !isWorkday(date) && date.Before(limit) {
+		date = date.AddDate(0, 0, 1)
+	}
+	return date
+}
+
+// addWorkdays returns the date the given number of working days after date.
+// A zero time means the calendar has no working days within the search limit.
+func addWorkdays(date time.Time, days int) time.Time {
+	limit := date.AddDate(searchLimitYears, 0, 0)
+	for days > 0 && date.Before(limit) {
+		date = date.AddDate(0, 0, 1)
+		if isWorkday(date) {
+			days--
+		}
+	}
+	if days > 0 {
+		return time.Time{}
+	}
+	return date
+}
+
+// workdaysBetween returns the number of working days after from up to and
+// including to. Weekdays are counted by whole weeks and the calendar days in
+// between are taken into account one by one.
+func workdaysBetween(from, to time.Time) int {
+	days := dayNumber(to) - dayNumber(from)
+	workdays := days / 7 * 5
+	for date := from.AddDate(0, 0, days/7*7+1); !date.After(to); date = date.AddDate(0, 0, 1) {
+		if isWeekday(date) {
+			workdays++
+		}
+	}
+
+	fromString, toString := from.Format(formatDate), to.Format(formatDate)
+
+	calendar.RLock()
+	defer calendar.RUnlock()
+	for dateString, working := range calendar.working {
+		if dateString <= fromString || dateString > toString {
+			continue
+		}
+		date, err := time.Parse(formatDate, dateString)
+		if err != nil {
+			continue
+		}
+		if working && !isWeekday(date) {
+			workdays++
+		}
+		if !working && isWeekday(date) {
+			workdays--
+		}
+	}
+	return workdays
+}
+
+// lastWorkday returns the date the given number of working days after start,
+// searching back from date, which is expected to be that far already.
+func lastWorkday(start, date time.Time, workdays int) time.Time {
+	count := workdaysBetween(start, date)
+	for workdays > 0 && date.After(start) {
+		if isWorkday(date) {
+			if count == workdays {
+				return date
+			}
+			count--
+		}
+		date = date.AddDate(0, 0, -1)
+	}
+	return start
+}
+
+func CalendarHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		GetCalendarHandler(w, r)
+	case http.MethodPost:
+		SetCalendarDayHandler(w, r)
+	case http.MethodDelete:
+		DeleteCalendarDayHandler(w, r)
+	default:
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+	}
+}
+
+func GetCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	days, err := db.CalendarDays()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting calendar error:", err)
+		writeJson(w, map[string]string{"error": "getting calendar error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, CalendarResp{
+		Days: days,
+	})
+}
+
+func SetCalendarDayHandler(w http.ResponseWriter, r *http.Request) {
+	var day db.CalendarDay
+
+	err := json.NewDecoder(r.Body).Decode(&day)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	if _, err := time.Parse(formatDate, day.Date); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong date format:", err)
+		writeJson(w, map[string]string{"error": "wrong date format"})
+		return
+	}
+
+	err = db.SetCalendarDay(&day)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("set calendar day error:", err)
+		writeJson(w, map[string]string{"error": "set calendar day error"})
+		return
+	}
+
+	err = loadCalendar()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("loading calendar error:", err)
+		writeJson(w, map[string]string{"error": "loading calendar error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
+
+func DeleteCalendarDayHandler(w http.ResponseWriter, r *http.Request) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("date cannot be empty")
+		writeJson(w, map[string]string{"error": "date cannot be empty"})
+		return
+	}
+
+	err := db.DeleteCalendarDay(date)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("delete calendar day error:", err)
+		writeJson(w, map[string]string{"error": fmt.Sprintf("calendar day %s not found", date)})
+		return
+	}
+
+	err = loadCalendar()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("loading calendar error:", err)
+		writeJson(w, map[string]string{"error": "loading calendar error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
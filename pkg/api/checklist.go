@@ -0,0 +1,254 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"finalProject/pkg/db"
+)
+
+type ChecklistResp struct {
+	Items    []*db.ChecklistItem `json:"items"`
+	Progress *db.Progress        `json:"progress"`
+}
+
+// ReorderChecklistReq lists the ids of all the checklist items of a task in
+// their new order.
+type ReorderChecklistReq struct {
+	TaskID string   `json:"task_id"`
+	IDs    []string `json:"ids"`
+}
+
+// checklistProgress counts the checked items.
+func checklistProgress(items []*db.ChecklistItem) *db.Progress {
+	progress := &db.Progress{Total: len(items)}
+	for _, item := range items {
+		if item.Done {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// findTask loads the task with the given id and writes an error response
+// when it is missing.
+func findTask(w http.ResponseWriter, idString string) (*db.Task, bool) {
+	if idString == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id cannot be empty")
+		writeJson(w, map[string]string{"error": "id cannot be empty"})
+		return nil, false
+	}
+
+	id, err := strconv.Atoi(idString)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("incorrect id:", err)
+		writeJson(w, map[string]string{"error": "incorrect id"})
+		return nil, false
+	}
+
+	task, err := db.GetTask(id)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("task not found:", err)
+		writeJson(w, map[string]string{"error": "task not found"})
+		return nil, false
+	}
+
+	return task, true
+}
+
+func ChecklistHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		AddChecklistItemHandler(w, r)
+	case http.MethodGet:
+		GetChecklistHandler(w, r)
+	case http.MethodDelete:
+		DeleteChecklistItemHandler(w, r)
+	default:
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+	}
+}
+
+func GetChecklistHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	if _, ok := findTask(w, idString); !ok {
+		return
+	}
+
+	items, err := db.ChecklistItems(idString)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting checklist error:", err)
+		writeJson(w, map[string]string{"error": "getting checklist error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, ChecklistResp{
+		Items:    items,
+		Progress: checklistProgress(items),
+	})
+}
+
+func AddChecklistItemHandler(w http.ResponseWriter, r *http.Request) {
+	var item db.ChecklistItem
+
+	err := json.NewDecoder(r.Body).Decode(&item)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	item.Title = strings.TrimSpace(item.Title)
+	if item.Title == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("title is empty")
+		writeJson(w, map[string]string{"error": "title is empty"})
+		return
+	}
+
+	if _, ok := findTask(w, item.TaskID); !ok {
+		return
+	}
+
+	id, err := db.AddChecklistItem(&item)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("add checklist item error:", err)
+		writeJson(w, map[string]string{"error": "add checklist item error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJson(w, map[string]any{"id": id})
+}
+
+func DeleteChecklistItemHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	if idString == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id cannot be empty")
+		writeJson(w, map[string]string{"error": "id cannot be empty"})
+		return
+	}
+
+	err := db.DeleteChecklistItem(idString)
+	if errors.Is(err, db.ErrChecklistItemNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("delete checklist item error:", err)
+		writeJson(w, map[string]string{"error": "checklist item not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("delete checklist item error:", err)
+		writeJson(w, map[string]string{"error": "delete checklist item error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
+
+func CheckItemHandler(w http.ResponseWriter, r *http.Request) {
+	setItemDone(w, r, true)
+}
+
+func UncheckItemHandler(w http.ResponseWriter, r *http.Request) {
+	setItemDone(w, r, false)
+}
+
+// setItemDone checks or unchecks the checklist item given by the id parameter.
+func setItemDone(w http.ResponseWriter, r *http.Request, done bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idString := r.URL.Query().Get("id")
+	if idString == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id cannot be empty")
+		writeJson(w, map[string]string{"error": "id cannot be empty"})
+		return
+	}
+
+	err := db.SetChecklistItemDone(idString, done)
+	if errors.Is(err, db.ErrChecklistItemNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("checklist item not found:", idString)
+		writeJson(w, map[string]string{"error": "checklist item not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("update checklist item error:", err)
+		writeJson(w, map[string]string{"error": "update checklist item error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
+
+func ReorderChecklistHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ReorderChecklistReq
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	if _, ok := findTask(w, req.TaskID); !ok {
+		return
+	}
+
+	seen := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("repeated checklist item:", id)
+			writeJson(w, map[string]string{"error": fmt.Sprintf("checklist item %s is listed twice", id)})
+			return
+		}
+		seen[id] = true
+	}
+
+	err = db.ReorderChecklist(req.TaskID, req.IDs)
+	if errors.Is(err, db.ErrChecklistOrder) {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("reorder checklist error:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, db.ErrChecklistItemNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("reorder checklist error:", err)
+		writeJson(w, map[string]string{"error": "checklist item not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("reorder checklist error:", err)
+		writeJson(w, map[string]string{"error": "reorder checklist error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
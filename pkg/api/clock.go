@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// Clock tells the time the api package takes for now.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// shiftedClock runs as the system clock moved by an offset.
+type shiftedClock struct {
+	offset time.Duration
+}
+
+func (c shiftedClock) Now() time.Time {
+	return time.Now().Add(c.offset)
+}
+
+// clock is used for every decision about today and the current time.
+var clock Clock = systemClock{}
+
+// SetClock replaces the clock of the package.
+func SetClock(c Clock) {
+	clock = c
+}
+
+// debug allows a request to give its own current date in the X-As-Of header.
+var debug bool
+
+// EnableDebug lets requests run as of the date given in the X-As-Of header.
+func EnableDebug() {
+	debug = true
+}
+
+// ClockAsOf returns a clock that starts from the given date in the server
+// time zone and keeps running from there.
+func ClockAsOf(value string) (Clock, error) {
+	now := time.Now()
+	asOf, err := parseAsOf(value, now.In(location))
+	if err != nil {
+		return nil, err
+	}
+	return shiftedClock{offset: asOf.Sub(now)}, nil
+}
+
+// parseAsOf reads a date or a date with time in the time zone of now. A date
+// alone keeps the time of day of now.
+func parseAsOf(value string, now time.Time) (time.Time, error) {
+	if asOf, err := time.ParseInLocation(formatDate+" "+formatTime, value, now.Location()); err == nil {
+		return asOf, nil
+	}
+	date, err := time.ParseInLocation(formatDate, value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("wrong as of date: %w", err)
+	}
+	return time.Date(date.Year(), date.Month(), date.Day(),
+		now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), now.Location()), nil
+}
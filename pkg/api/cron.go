@@ -0,0 +1,141 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Five-field cron expressions: minute, hour, day of month, month and day of
+// week. Tasks are scheduled by day, so minute and hour are only validated.
+// As in Vixie cron, when both day of month and day of week are restricted a
+// day matches either of them.
+
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+}
+
+func parseCron(fields []string) (Rule, error) {
+	if len(fields) != len(cronFields) {
+		return Rule{}, fmt.Errorf("cron expression should have %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	values := make([][]int, len(cronFields))
+	for i, field := range cronFields {
+		var err error
+		values[i], err = field.parse(strings.ToUpper(fields[i]))
+		if err != nil {
+			return Rule{}, fmt.Errorf("cron %s: %w", field.name, err)
+		}
+	}
+
+	rule := Rule{
+		Kind:      RuleCron,
+		Interval:  1,
+		Minutes:   values[0],
+		Hours:     values[1],
+		MonthDays: values[2],
+		Months:    values[3],
+		cron:      strings.Join(fields, " "),
+	}
+
+	if values[4] != nil {
+		for _, weekday := range values[4] {
+			if weekday == 0 {
+				weekday = 7
+			}
+			rule.Weekdays = append(rule.Weekdays, weekday)
+		}
+		slices.Sort(rule.Weekdays)
+		rule.Weekdays = slices.Compact(rule.Weekdays)
+		if len(rule.Weekdays) == 7 {
+			rule.Weekdays = nil
+		}
+	}
+
+	if len(rule.Weekdays) == 0 && !rule.canMatch() {
+		return Rule{}, fmt.Errorf("cron expression never matches")
+	}
+	return rule, nil
+}
+
+// parse returns the sorted values of the field, or nil when the field
+// covers its whole range.
+func (f cronField) parse(field string) ([]int, error) {
+	set := make(map[int]bool)
+
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepString, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepString)
+			if err != nil || step < 1 {
+				return nil, fmt.Errorf("wrong step %q", stepString)
+			}
+		}
+
+		from, to := f.min, f.max
+		if rangePart != "*" {
+			fromString, toString, isRange := strings.Cut(rangePart, "-")
+			var err error
+			from, err = f.value(fromString)
+			if err != nil {
+				return nil, err
+			}
+			to = from
+			if isRange {
+				to, err = f.value(toString)
+				if err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				to = f.max
+			}
+			if from > to {
+				return nil, fmt.Errorf("wrong range %q", rangePart)
+			}
+		}
+
+		for value := from; value <= to; value += step {
+			set[value] = true
+		}
+	}
+
+	if len(set) == f.max-f.min+1 {
+		return nil, nil
+	}
+
+	values := make([]int, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	slices.Sort(values)
+	return values, nil
+}
+
+func (f cronField) value(valueString string) (int, error) {
+	if index := slices.Index(f.names, valueString); index >= 0 && valueString != "" {
+		return index, nil
+	}
+	value, err := strconv.Atoi(valueString)
+	if err != nil {
+		return 0, fmt.Errorf("wrong value %q", valueString)
+	}
+	if value < f.min || value > f.max {
+		return 0, fmt.Errorf("value %d is out of range %d-%d", value, f.min, f.max)
+	}
+	return value, nil
+}
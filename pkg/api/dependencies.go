@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"finalProject/pkg/db"
+)
+
+// Dependency makes the task TaskID wait for the task DependsOn.
+type Dependency struct {
+	TaskID    string `json:"task_id"`
+	DependsOn string `json:"depends_on"`
+}
+
+func DependenciesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		AddDependencyHandler(w, r)
+	case http.MethodGet:
+		GetDependenciesHandler(w, r)
+	case http.MethodDelete:
+		DeleteDependencyHandler(w, r)
+	default:
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+	}
+}
+
+func GetDependenciesHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	if _, ok := findTask(w, idString); !ok {
+		return
+	}
+
+	deps, err := db.GetDependencies(idString)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting dependencies error:", err)
+		writeJson(w, map[string]string{"error": "getting dependencies error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, deps)
+}
+
+func AddDependencyHandler(w http.ResponseWriter, r *http.Request) {
+	var dep Dependency
+
+	err := json.NewDecoder(r.Body).Decode(&dep)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	task, ok := findTask(w, dep.TaskID)
+	if !ok {
+		return
+	}
+	blocker, ok := findTask(w, dep.DependsOn)
+	if !ok {
+		return
+	}
+
+	err = db.AddDependency(task.ID, blocker.ID)
+	if errors.Is(err, db.ErrDependencyCycle) {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("add dependency error:", err)
+		writeJson(w, map[string]string{"error": "the task cannot wait for itself"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("add dependency error:", err)
+		writeJson(w, map[string]string{"error": "add dependency error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJson(w, map[string]any{})
+}
+
+func DeleteDependencyHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	dependsOn := r.URL.Query().Get("depends_on")
+	if idString == "" || dependsOn == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id and depends_on cannot be empty")
+		writeJson(w, map[string]string{"error": "id and depends_on cannot be empty"})
+		return
+	}
+
+	err := db.DeleteDependency(idString, dependsOn)
+	if errors.Is(err, db.ErrDependencyNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("delete dependency error:", err)
+		writeJson(w, map[string]string{"error": "dependency not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("delete dependency error:", err)
+		writeJson(w, map[string]string{"error": "delete dependency error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
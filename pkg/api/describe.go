@@ -0,0 +1,589 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	langRu = "ru"
+	langEn = "en"
+)
+
+// Describe returns the repeat rule as a sentence in Russian or English, e.g.
+// "каждые 14 дней" or "on the last day of every month".
+func Describe(repeat string, lang string) (string, error) {
+	rule, err := ParseRepeat(repeat)
+	if err != nil {
+		return "", err
+	}
+
+	switch lang {
+	case langRu:
+		return describeRu(rule), nil
+	case langEn:
+		return describeEn(rule), nil
+	}
+	return "", fmt.Errorf("unsupported language %q", lang)
+}
+
+// requestLang returns the language asked for by the lang parameter or the
+// Accept-Language header. Russian is the default and is also used for
+// languages that are not supported.
+func requestLang(r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		if lang == langEn {
+			return langEn
+		}
+		return langRu
+	}
+	if strings.HasPrefix(r.Header.Get("Accept-Language"), langEn) {
+		return langEn
+	}
+	return langRu
+}
+
+func DescribeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	description, err := Describe(r.URL.Query().Get("repeat"), requestLang(r))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("describe error:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, map[string]string{"description": description})
+}
+
+// joinWords joins the words with commas and the conjunction before the last one.
+func joinWords(words []string, conjunction string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " " + conjunction + " " + words[len(words)-1]
+}
+
+func hasFeb29(dates []MonthDay) bool {
+	return slices.Contains(dates, MonthDay{Month: 2, Day: 29})
+}
+
+// leapNote reports whether the rule needs a note on February 29.
+func (r Rule) leapNote() bool {
+	if r.Leap == LeapDefault {
+		return hasFeb29(r.Dates)
+	}
+	return len(r.Dates) == 0 || hasFeb29(r.Dates)
+}
+
+// singleTime returns the time of a cron rule that fires once a day.
+func (r Rule) singleTime() (string, bool) {
+	if len(r.Hours) != 1 || len(r.Minutes) != 1 {
+		return "", false
+	}
+	return fmt.Sprintf("%d:%02d", r.Hours[0], r.Minutes[0]), true
+}
+
+// English
+
+var enUnits = map[RuleKind][2]string{
+	RuleDaily:        {"day", "days"},
+	RuleWeekly:       {"week", "weeks"},
+	RuleMonthly:      {"month", "months"},
+	RuleYearly:       {"year", "years"},
+	RuleBusinessDays: {"working day", "working days"},
+	RuleHourly:       {"hour", "hours"},
+}
+
+var enOrdinals = []string{"", "first", "second", "third", "fourth", "fifth"}
+
+func describeEn(r Rule) string {
+	var text string
+	switch {
+	case r.Kind == RuleCron:
+		text = enCron(r)
+	case r.Kind == RuleYearly && !r.rfc:
+		text = "every year"
+		if len(r.Dates) > 0 {
+			dates := make([]string, len(r.Dates))
+			for i, d := range r.Dates {
+				dates[i] = fmt.Sprintf("%s %d", time.Month(d.Month), d.Day)
+			}
+			text += " on " + joinWords(dates, "and")
+		}
+		if r.leapNote() {
+			switch r.Leap {
+			case LeapOnly:
+				text += ", February 29 only in leap years"
+			case LeapFeb28:
+				text += ", February 29 moves to February 28 in other years"
+			default:
+				text += ", February 29 moves to March 1 in other years"
+			}
+		}
+	default:
+		text = enPeriodic(r)
+	}
+
+	if !r.Until.IsZero() {
+		text += " until " + r.Until.Format("January 2, 2006")
+	}
+	if r.Count == 1 {
+		text += ", once"
+	} else if r.Count > 1 {
+		text += fmt.Sprintf(", %d times", r.Count)
+	}
+	if r.Shift {
+		text += ", shifted to the next working day"
+	}
+	return text
+}
+
+func enEvery(interval int, kind RuleKind) string {
+	if interval <= 1 {
+		return "every " + enUnits[kind][0]
+	}
+	return fmt.Sprintf("every %d %s", interval, enUnits[kind][1])
+}
+
+func enPeriodic(r Rule) string {
+	period := enEvery(r.Interval, r.Kind)
+	days, condition := enDays(r)
+	months := enMonths(r.Months)
+	if condition != "" {
+		condition = " if it is " + condition
+	}
+
+	switch {
+	case days == "" && months == "":
+		return period
+	case days == "":
+		return period + " in " + months
+	case r.Kind == RuleMonthly && months != "" && r.Interval <= 1:
+		return days + " of " + months + condition
+	case r.Kind == RuleMonthly && months != "":
+		return days + " of " + period + " in " + months + condition
+	case r.Kind == RuleMonthly:
+		return days + " of " + period + condition
+	case r.Kind == RuleYearly && months != "":
+		return period + " " + days + " of " + months + condition
+	case r.Kind == RuleYearly:
+		return period + " " + days + condition
+	}
+
+	// weekly and daily rules pick days by weekdays and days of month
+	if months != "" {
+		months = " in " + months
+	}
+	if r.Interval <= 1 && len(r.MonthDays) == 0 && len(r.NthWeekdays) == 0 {
+		return "every " + enWeekdays(r.Weekdays) + months
+	}
+	if r.Interval <= 1 {
+		return days + months + condition
+	}
+	return period + " " + days + months + condition
+}
+
+// enDays describes the days picked by weekdays and days of month. When both
+// are given, the weekdays are returned apart as a condition on the days.
+func enDays(r Rule) (string, string) {
+	var weekdays, conditions []string
+	for _, weekday := range r.Weekdays {
+		weekdays = append(weekdays, enWeekday(weekday)+"s")
+		conditions = append(conditions, "a "+enWeekday(weekday))
+	}
+	for _, nth := range r.NthWeekdays {
+		weekday := "the " + enNth(nth.N) + " " + enWeekday(nth.Weekday)
+		if r.Kind == RuleWeekly || r.Kind == RuleDaily {
+			weekday += " of the month"
+		}
+		weekdays = append(weekdays, weekday)
+		conditions = append(conditions, weekday)
+	}
+
+	if len(r.MonthDays) == 0 {
+		if len(weekdays) == 0 {
+			return "", ""
+		}
+		return "on " + joinWords(weekdays, "and"), ""
+	}
+
+	var days []string
+	for _, day := range r.MonthDays {
+		if day > 0 {
+			days = append(days, "the "+enNumber(day))
+		}
+	}
+	for _, day := range r.MonthDays {
+		if day < 0 {
+			days = append(days, "the "+enNth(day)+" day")
+		}
+	}
+	return "on " + joinWords(days, "and"), joinWords(conditions, "or")
+}
+
+func enWeekday(weekday int) string {
+	return time.Weekday(weekday % 7).String()
+}
+
+func enWeekdays(weekdays []int) string {
+	names := make([]string, len(weekdays))
+	for i, weekday := range weekdays {
+		names[i] = enWeekday(weekday)
+	}
+	return joinWords(names, "and")
+}
+
+func enMonths(months []int) string {
+	names := make([]string, len(months))
+	for i, month := range months {
+		names[i] = time.Month(month).String()
+	}
+	return joinWords(names, "and")
+}
+
+// enNth returns "first", "last", "second to last" and so on.
+func enNth(n int) string {
+	switch {
+	case n == -1:
+		return "last"
+	case n < 0:
+		return enNth(-n) + " to last"
+	case n < len(enOrdinals):
+		return enOrdinals[n]
+	}
+	return enNumber(n)
+}
+
+// enNumber returns "1st", "2nd", "11th" and so on.
+func enNumber(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+func enCron(r Rule) string {
+	var parts []string
+	if len(r.MonthDays) > 0 {
+		days := make([]string, len(r.MonthDays))
+		for i, day := range r.MonthDays {
+			days[i] = "the " + enNumber(day)
+		}
+		parts = append(parts, "on "+joinWords(days, "and"))
+	}
+	if len(r.Weekdays) > 0 {
+		parts = append(parts, "every "+enWeekdays(r.Weekdays))
+	}
+
+	text := joinWords(parts, "or")
+	switch {
+	case text == "":
+		text = "every day"
+	case len(r.Weekdays) == 0 && len(r.Months) == 0:
+		text += " of every month"
+	}
+	if len(r.Months) > 0 {
+		text += " in " + enMonths(r.Months)
+	}
+	if at, ok := r.singleTime(); ok {
+		text += " at " + at
+	}
+	return text
+}
+
+// Russian
+
+type ruUnit struct {
+	each           string
+	one, few, many string
+}
+
+var ruUnits = map[RuleKind]ruUnit{
+	RuleDaily:        {"каждый", "день", "дня", "дней"},
+	RuleWeekly:       {"каждую", "неделю", "недели", "недель"},
+	RuleMonthly:      {"каждый", "месяц", "месяца", "месяцев"},
+	RuleYearly:       {"каждый", "год", "года", "лет"},
+	RuleBusinessDays: {"каждый", "рабочий день", "рабочих дня", "рабочих дней"},
+	RuleHourly:       {"каждый", "час", "часа", "часов"},
+}
+
+type ruGender int
+
+const (
+	ruMasculine ruGender = iota
+	ruFeminine
+	ruNeuter
+)
+
+type ruWeekday struct {
+	nominative, accusative, dativePlural string
+	gender                               ruGender
+}
+
+var ruWeekdays = []ruWeekday{
+	{},
+	{"понедельник", "понедельник", "понедельникам", ruMasculine},
+	{"вторник", "вторник", "вторникам", ruMasculine},
+	{"среда", "среду", "средам", ruFeminine},
+	{"четверг", "четверг", "четвергам", ruMasculine},
+	{"пятница", "пятницу", "пятницам", ruFeminine},
+	{"суббота", "субботу", "субботам", ruFeminine},
+	{"воскресенье", "воскресенье", "воскресеньям", ruNeuter},
+}
+
+var ruMonthsGenitive = []string{"", "января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря"}
+
+var ruMonthsPrepositional = []string{"", "январе", "феврале", "марте", "апреле", "мае", "июне",
+	"июле", "августе", "сентябре", "октябре", "ноябре", "декабре"}
+
+// ordinals from "первый" to "пятый", "последний" and "предпоследний"
+var ruOrdinals = map[ruGender][7]string{
+	ruMasculine: {"первый", "второй", "третий", "четвёртый", "пятый", "последний", "предпоследний"},
+	ruFeminine:  {"первая", "вторая", "третья", "четвёртая", "пятая", "последняя", "предпоследняя"},
+	ruNeuter:    {"первое", "второе", "третье", "четвёртое", "пятое", "последнее", "предпоследнее"},
+}
+
+var ruFeminineAccusative = [7]string{"первую", "вторую", "третью", "четвёртую", "пятую", "последнюю", "предпоследнюю"}
+
+func describeRu(r Rule) string {
+	var text string
+	switch {
+	case r.Kind == RuleCron:
+		text = ruCron(r)
+	case r.Kind == RuleYearly && !r.rfc:
+		text = "каждый год"
+		if len(r.Dates) > 0 {
+			dates := make([]string, len(r.Dates))
+			for i, d := range r.Dates {
+				dates[i] = fmt.Sprintf("%d %s", d.Day, ruMonthsGenitive[d.Month])
+			}
+			text += " " + joinWords(dates, "и")
+		}
+		if r.leapNote() {
+			switch r.Leap {
+			case LeapOnly:
+				text += ", 29 февраля только в високосные годы"
+			case LeapFeb28:
+				text += ", в невисокосные годы 29 февраля переносится на 28 февраля"
+			default:
+				text += ", в невисокосные годы 29 февраля переносится на 1 марта"
+			}
+		}
+	default:
+		text = ruPeriodic(r)
+	}
+
+	if !r.Until.IsZero() {
+		text += fmt.Sprintf(" до %d %s %d", r.Until.Day(), ruMonthsGenitive[r.Until.Month()], r.Until.Year())
+	}
+	if r.Count > 0 {
+		text += fmt.Sprintf(", %d %s", r.Count, ruPlural(r.Count, "раз", "раза", "раз"))
+	}
+	if r.Shift {
+		text += ", с переносом на следующий рабочий день"
+	}
+	return text
+}
+
+// ruPlural picks the form of the noun for the number: "1 день", "2 дня",
+// "5 дней".
+func ruPlural(n int, one, few, many string) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return one
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return few
+	}
+	return many
+}
+
+func ruEvery(interval int, kind RuleKind) string {
+	unit := ruUnits[kind]
+	if interval <= 1 {
+		return unit.each + " " + unit.one
+	}
+	if interval%10 == 1 && interval%100 != 11 {
+		return fmt.Sprintf("%s %d %s", unit.each, interval, unit.one)
+	}
+	return fmt.Sprintf("каждые %d %s", interval, ruPlural(interval, unit.one, unit.few, unit.many))
+}
+
+// ruIn adds the preposition "в", which turns into "во" before "вт".
+func ruIn(word string) string {
+	if strings.HasPrefix(word, "вт") {
+		return "во " + word
+	}
+	return "в " + word
+}
+
+func ruOrdinal(n int, gender ruGender, accusative bool) string {
+	words := ruOrdinals[gender]
+	if accusative && gender == ruFeminine {
+		words = ruFeminineAccusative
+	}
+	switch {
+	case n == -1:
+		return words[5]
+	case n == -2:
+		return words[6]
+	case n < 0:
+		return words[-n-1] + " с конца"
+	}
+	return words[n-1]
+}
+
+func ruPeriodic(r Rule) string {
+	period := ruEvery(r.Interval, r.Kind)
+	days, pickedDays := ruDays(r)
+
+	var months string
+	if len(r.Months) > 0 {
+		names := make([]string, len(r.Months))
+		for i, month := range r.Months {
+			if pickedDays {
+				names[i] = ruMonthsGenitive[month]
+			} else {
+				names[i] = ruMonthsPrepositional[month]
+			}
+		}
+		months = joinWords(names, "и")
+		if !pickedDays {
+			months = "в " + months
+		}
+	}
+
+	parts := []string{}
+	switch {
+	case days == "" || r.Interval > 1:
+		parts = append(parts, period)
+	case r.Kind == RuleMonthly && months == "":
+		parts = append(parts, period)
+	case r.Kind == RuleYearly:
+		parts = append(parts, period)
+	}
+	if days != "" {
+		parts = append(parts, days)
+	}
+	if months != "" {
+		parts = append(parts, months)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ruDays describes the days picked by weekdays and days of month and reports
+// whether they are days of a month, so that a month name follows in the
+// genitive case.
+func ruDays(r Rule) (string, bool) {
+	var dative []string
+	for _, weekday := range r.Weekdays {
+		dative = append(dative, ruWeekdays[weekday].dativePlural)
+	}
+
+	if len(r.MonthDays) == 0 {
+		var parts []string
+		if len(dative) > 0 {
+			parts = append(parts, "по "+joinWords(dative, "и"))
+		}
+		for _, nth := range r.NthWeekdays {
+			weekday := ruWeekdays[nth.Weekday]
+			part := ruIn(ruOrdinal(nth.N, weekday.gender, true) + " " + weekday.accusative)
+			if r.Kind == RuleWeekly || r.Kind == RuleDaily {
+				part += " месяца"
+			}
+			parts = append(parts, part)
+		}
+		return joinWords(parts, "и"), len(dative) == 0 && len(parts) > 0
+	}
+
+	var numbers, ordinals []string
+	for _, day := range r.MonthDays {
+		if day > 0 {
+			numbers = append(numbers, fmt.Sprintf("%d-го", day))
+		} else {
+			ordinals = append(ordinals, "в "+ruOrdinal(day, ruMasculine, true)+" день")
+		}
+	}
+
+	var parts []string
+	if len(numbers) > 0 {
+		part := joinWords(numbers, "и")
+		if len(ordinals) > 0 {
+			part = strings.Join(numbers, ", ")
+		}
+		if len(r.Months) == 0 {
+			part += " числа"
+		}
+		parts = append(parts, part)
+	}
+	parts = append(parts, ordinals...)
+	text := joinWords(parts, "и")
+
+	var weekdays []string
+	for _, weekday := range r.Weekdays {
+		weekdays = append(weekdays, ruWeekdays[weekday].nominative)
+	}
+	for _, nth := range r.NthWeekdays {
+		weekday := ruWeekdays[nth.Weekday]
+		weekdays = append(weekdays, ruOrdinal(nth.N, weekday.gender, false)+" "+weekday.nominative+" месяца")
+	}
+	if len(weekdays) > 0 {
+		text += ", если это " + joinWords(weekdays, "или")
+	}
+	return text, true
+}
+
+func ruCron(r Rule) string {
+	var parts []string
+	if len(r.MonthDays) > 0 {
+		days := make([]string, len(r.MonthDays))
+		for i, day := range r.MonthDays {
+			days[i] = fmt.Sprintf("%d-го", day)
+		}
+		parts = append(parts, joinWords(days, "и")+" числа")
+	}
+	if len(r.Weekdays) > 0 {
+		dative := make([]string, len(r.Weekdays))
+		for i, weekday := range r.Weekdays {
+			dative[i] = ruWeekdays[weekday].dativePlural
+		}
+		parts = append(parts, "по "+joinWords(dative, "и"))
+	}
+
+	text := joinWords(parts, "или")
+	switch {
+	case text == "":
+		text = "каждый день"
+	case len(r.Weekdays) == 0 && len(r.Months) == 0:
+		text = "каждый месяц " + text
+	}
+	if len(r.Months) > 0 {
+		months := make([]string, len(r.Months))
+		for i, month := range r.Months {
+			months[i] = ruMonthsPrepositional[month]
+		}
+		text += " в " + joinWords(months, "и")
+	}
+	if at, ok := r.singleTime(); ok {
+		text += " в " + at
+	}
+	return text
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"finalProject/pkg/db"
+)
+
+type ExceptionsResp struct {
+	Exceptions []*db.Exception `json:"exceptions"`
+}
+
+// originalDate returns the date of the pending occurrence before it was
+// moved by an exception.
+func originalDate(task *db.Task, exceptions []*db.Exception) string {
+	for _, exception := range exceptions {
+		if exception.MovedTo != "" && exception.MovedTo == task.Date {
+			return exception.Date
+		}
+	}
+	return task.Date
+}
+
+// nextTaskOccurrence returns the date a repeating task moves to after now and
+// the original date of that occurrence. Skipped occurrences are passed over
+// and moved or shifted ones are returned with their new date. The remaining
+// count is tracked by the task itself, since its date moves.
+func nextTaskOccurrence(now time.Time, task *db.Task, exceptions []*db.Exception) (time.Time, time.Time, error) {
+	rule, err := ParseRepeat(task.Repeat)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	rule.Count = 0
+
+	start, err := time.Parse(formatDate, originalDate(task, exceptions))
+	if rule.Kind == RuleHourly {
+		start, err = time.Parse(formatDate+formatTime, task.Date+task.Time)
+	}
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("incorrect start date: %w", err)
+	}
+
+	movedTo := make(map[string]string)
+	for _, exception := range exceptions {
+		movedTo[exception.Date] = exception.MovedTo
+	}
+
+	// every skipped occurrence uses up one exception
+	for range len(exceptions) + 1 {
+		next, _, err := nextOccurrence(rule, now, start)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+
+		moved, ok := movedTo[next.Format(formatDate)]
+		if !ok {
+			return rule.shifted(next), next, nil
+		}
+		if moved != "" {
+			movedDate, err := time.Parse(formatDate, moved)
+			if err != nil {
+				return time.Time{}, time.Time{}, fmt.Errorf("incorrect moved date: %w", err)
+			}
+			return movedDate, next, nil
+		}
+		start = next
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("all occurrences are skipped")
+}
+
+// keepOriginalDate records a shifted occurrence as a moved one, so that the
+// series goes on from its original date.
+func keepOriginalDate(taskID string, next, original time.Time) error {
+	if next.Equal(original) {
+		return nil
+	}
+	return db.AddException(&db.Exception{
+		TaskID:  taskID,
+		Date:    original.Format(formatDate),
+		MovedTo: next.Format(formatDate),
+	})
+}
+
+// isOccurrence reports whether date is an occurrence of the repeat rule
+// starting from start.
+func isOccurrence(repeat string, start time.Time, date time.Time) (bool, error) {
+	rule, err := ParseRepeat(repeat)
+	if err != nil {
+		return false, err
+	}
+	rule.Count = 0
+
+	if !start.Before(date) {
+		return start.Equal(date), nil
+	}
+
+	next, _, err := nextOccurrence(rule, date.AddDate(0, 0, -1), start)
+	if errors.Is(err, ErrRuleEnded) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return next.Equal(date), nil
+}
+
+func ExceptionsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		AddExceptionHandler(w, r)
+	case http.MethodGet:
+		GetExceptionsHandler(w, r)
+	case http.MethodDelete:
+		DeleteExceptionHandler(w, r)
+	default:
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+	}
+}
+
+// repeatingTask loads the task with the given id and writes an error response
+// when it is missing or not repeating.
+func repeatingTask(w http.ResponseWriter, idString string) (*db.Task, bool) {
+	task, ok := findTask(w, idString)
+	if !ok {
+		return nil, false
+	}
+
+	if task.Repeat == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("task is not repeating")
+		writeJson(w, map[string]string{"error": "task is not repeating"})
+		return nil, false
+	}
+
+	return task, true
+}
+
+func GetExceptionsHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	if _, ok := repeatingTask(w, idString); !ok {
+		return
+	}
+
+	exceptions, err := db.Exceptions(idString)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting exceptions error:", err)
+		writeJson(w, map[string]string{"error": "getting exceptions error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, ExceptionsResp{
+		Exceptions: exceptions,
+	})
+}
+
+func AddExceptionHandler(w http.ResponseWriter, r *http.Request) {
+	var exception db.Exception
+
+	err := json.NewDecoder(r.Body).Decode(&exception)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	task, ok := repeatingTask(w, exception.TaskID)
+	if !ok {
+		return
+	}
+
+	if rule, err := ParseRepeat(task.Repeat); err == nil && rule.Kind == RuleHourly {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("exception for hourly task:", task.ID)
+		writeJson(w, map[string]string{"error": "exceptions are not supported for hourly rules"})
+		return
+	}
+
+	exceptions, err := db.Exceptions(exception.TaskID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting exceptions error:", err)
+		writeJson(w, map[string]string{"error": "getting exceptions error"})
+		return
+	}
+
+	if exception.Date == "" {
+		exception.Date = originalDate(task, exceptions)
+	}
+
+	date, err := time.Parse(formatDate, exception.Date)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong date format:", err)
+		writeJson(w, map[string]string{"error": "wrong date format"})
+		return
+	}
+
+	if exception.MovedTo != "" {
+		if _, err := time.Parse(formatDate, exception.MovedTo); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("wrong moved_to format:", err)
+			writeJson(w, map[string]string{"error": "wrong moved_to format"})
+			return
+		}
+	}
+
+	pending, err := time.Parse(formatDate, originalDate(task, exceptions))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("incorrect task date:", err)
+		writeJson(w, map[string]string{"error": "incorrect task date"})
+		return
+	}
+
+	ok, err = isOccurrence(task.Repeat, pending, date)
+	if err != nil || !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("not an upcoming occurrence:", exception.Date, err)
+		writeJson(w, map[string]string{"error": "date is not an upcoming occurrence"})
+		return
+	}
+
+	err = db.AddException(&exception)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("add exception error:", err)
+		writeJson(w, map[string]string{"error": "add exception error"})
+		return
+	}
+
+	if date.Equal(pending) {
+		newDate := exception.MovedTo
+		if newDate == "" {
+			exceptions = append(exceptions, &exception)
+			next, original, err := nextTaskOccurrence(date, task, exceptions)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				log.Println("error next occurrence:", err)
+				writeJson(w, map[string]string{"error": "error next occurrence"})
+				return
+			}
+			newDate = next.Format(formatDate)
+
+			err = keepOriginalDate(exception.TaskID, next, original)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				log.Println("add exception error:", err)
+				writeJson(w, map[string]string{"error": "add exception error"})
+				return
+			}
+		}
+
+		err = db.UpdateDate(newDate, exception.TaskID)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("update data error:", err)
+			writeJson(w, map[string]string{"error": "update data error"})
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJson(w, map[string]any{})
+}
+
+func DeleteExceptionHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	dateString := r.URL.Query().Get("date")
+
+	task, ok := repeatingTask(w, idString)
+	if !ok {
+		return
+	}
+
+	exceptions, err := db.Exceptions(idString)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting exceptions error:", err)
+		writeJson(w, map[string]string{"error": "getting exceptions error"})
+		return
+	}
+	pending := originalDate(task, exceptions)
+
+	var removed *db.Exception
+	for _, exception := range exceptions {
+		if exception.Date == dateString {
+			removed = exception
+		}
+	}
+	if removed == nil {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("exception not found:", dateString)
+		writeJson(w, map[string]string{"error": "exception not found"})
+		return
+	}
+
+	err = db.DeleteException(idString, dateString)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("delete exception error:", err)
+		writeJson(w, map[string]string{"error": "delete exception error"})
+		return
+	}
+
+	// the restored occurrence becomes pending again if it comes first
+	if removed.Date <= pending {
+		err = db.UpdateDate(removed.Date, idString)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Println("update data error:", err)
+			writeJson(w, map[string]string{"error": "update data error"})
+			return
+		}
+	}
+
+	writeJson(w, map[string]any{})
+}
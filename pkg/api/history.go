@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"finalProject/pkg/db"
+)
+
+// most completions returned by /api/history
+const historyLimit = 500
+
+type HistoryResp struct {
+	History []*db.HistoryEntry `json:"history"`
+}
+
+// historyEntry records the task as done at now. The task is copied, so that
+// rescheduling it does not change the entry.
+func historyEntry(task *db.Task, now time.Time) *db.HistoryEntry {
+	snapshot := *task
+	return &db.HistoryEntry{
+		TaskID: task.ID,
+		Date:   task.Date,
+		DoneAt: storedStamp(now),
+		Task:   &snapshot,
+	}
+}
+
+// historyDate reads a date of the history range as its midnight in the time
+// zone loc. An empty date gives the zero time.
+func historyDate(name string, value string, loc *time.Location) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation(formatDate, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s should be a date in the format %s", name, formatDate)
+	}
+	return date, nil
+}
+
+// HistoryHandler returns the tasks done from the date from to the date to
+// inclusive in the time zone of the request, optionally of one task.
+func HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	loc, err := requestLocation(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("time zone error:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	from, err := historyDate("from", r.URL.Query().Get("from"), loc)
+	var to time.Time
+	if err == nil {
+		to, err = historyDate("to", r.URL.Query().Get("to"), loc)
+	}
+	if err == nil && !from.IsZero() && !to.IsZero() && from.After(to) {
+		err = fmt.Errorf("from should not be after to")
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong history range:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	filter := db.HistoryFilter{
+		TaskID: r.URL.Query().Get("task_id"),
+		Limit:  historyLimit,
+	}
+	if !from.IsZero() {
+		filter.From = storedStamp(from)
+	}
+	if !to.IsZero() {
+		filter.Before = storedStamp(to.AddDate(0, 0, 1))
+	}
+
+	history, err := db.History(filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting history error:", err)
+		writeJson(w, map[string]string{"error": "getting history error"})
+		return
+	}
+
+	for _, entry := range history {
+		entry.DoneAt = localStamp(entry.DoneAt, loc)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, HistoryResp{
+		History: history,
+	})
+}
@@ -0,0 +1,461 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Phrases like "каждую вторую пятницу", "every month on the 1st" or "по
+// понедельникам и средам" are read word by word: numbers, ordinals, weekdays,
+// months and units. Words that mean nothing for the rule, such as "every" or
+// "каждый", are skipped and any other word is an error, so that a phrase is
+// never half understood.
+
+var numberPattern = regexp.MustCompile(`^(\d+)(st|nd|rd|th|-?го|-?ого|-?е|-?ое|-?й|-?я)?$`)
+
+var naturalFillers = map[string]bool{
+	"every": true, "each": true, "on": true, "the": true, "of": true, "and": true, "a": true,
+	"an": true, "in": true, "at": true, "to": true,
+	"каждый": true, "каждую": true, "каждое": true, "каждые": true, "каждого": true, "каждой": true,
+	"каждым": true, "по": true, "в": true, "во": true, "и": true, "раз": true, "число": true,
+	"числа": true, "числам": true,
+}
+
+var naturalNumbers = map[string]int{
+	"two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	"два": 2, "две": 2, "три": 3, "четыре": 4, "пять": 5, "шесть": 6, "семь": 7, "восемь": 8,
+	"девять": 9, "десять": 10,
+}
+
+var naturalOrdinals = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1, "penultimate": -2,
+	"первый": 1, "первую": 1, "первое": 1, "первая": 1, "первого": 1,
+	"второй": 2, "вторую": 2, "второе": 2, "вторая": 2, "второго": 2,
+	"третий": 3, "третью": 3, "третье": 3, "третья": 3, "третьего": 3,
+	"четвертый": 4, "четвертую": 4, "четвертое": 4, "четвертая": 4, "четвертого": 4,
+	"пятый": 5, "пятую": 5, "пятое": 5, "пятая": 5, "пятого": 5,
+	"последний": -1, "последнюю": -1, "последнее": -1, "последняя": -1, "последнего": -1,
+	"предпоследний": -2, "предпоследнюю": -2, "предпоследнее": -2, "предпоследняя": -2,
+}
+
+var naturalWeekdays = map[string]int{
+	"monday": 1, "mondays": 1, "mon": 1,
+	"tuesday": 2, "tuesdays": 2, "tue": 2, "tues": 2,
+	"wednesday": 3, "wednesdays": 3, "wed": 3,
+	"thursday": 4, "thursdays": 4, "thu": 4, "thurs": 4,
+	"friday": 5, "fridays": 5, "fri": 5,
+	"saturday": 6, "saturdays": 6, "sat": 6,
+	"sunday": 7, "sundays": 7, "sun": 7,
+}
+
+var naturalMonths = map[string]int{
+	"january": 1, "jan": 1, "february": 2, "feb": 2, "march": 3, "mar": 3, "april": 4, "apr": 4,
+	"may": 5, "май": 5, "мая": 5, "мае": 5, "june": 6, "jun": 6, "july": 7, "jul": 7,
+	"august": 8, "aug": 8, "september": 9, "sep": 9, "sept": 9, "october": 10, "oct": 10,
+	"november": 11, "nov": 11, "december": 12, "dec": 12,
+}
+
+// beginnings of Russian words in any case, Monday and January first
+var (
+	ruWeekdayStems = []string{"понедельник", "вторник", "сред", "четверг", "пятниц", "суббот", "воскресен"}
+	ruMonthStems   = []string{"январ", "феврал", "март", "апрел", "", "июн", "июл", "август", "сентябр", "октябр", "ноябр", "декабр"}
+)
+
+var naturalUnits = map[string]RuleKind{
+	"day": RuleDaily, "days": RuleDaily, "daily": RuleDaily,
+	"день": RuleDaily, "дня": RuleDaily, "дней": RuleDaily, "дням": RuleDaily, "ежедневно": RuleDaily,
+	"week": RuleWeekly, "weeks": RuleWeekly, "weekly": RuleWeekly,
+	"неделю": RuleWeekly, "недели": RuleWeekly, "недель": RuleWeekly, "неделя": RuleWeekly, "еженедельно": RuleWeekly,
+	"month": RuleMonthly, "months": RuleMonthly, "monthly": RuleMonthly,
+	"месяц": RuleMonthly, "месяца": RuleMonthly, "месяцев": RuleMonthly, "ежемесячно": RuleMonthly,
+	"year": RuleYearly, "years": RuleYearly, "yearly": RuleYearly, "annually": RuleYearly,
+	"год": RuleYearly, "года": RuleYearly, "лет": RuleYearly, "ежегодно": RuleYearly,
+	"workday": RuleBusinessDays, "workdays": RuleBusinessDays,
+	"hour": RuleHourly, "hours": RuleHourly, "hourly": RuleHourly,
+	"час": RuleHourly, "часа": RuleHourly, "часов": RuleHourly, "ежечасно": RuleHourly,
+}
+
+var (
+	workingWords  = []string{"working", "business", "рабочий", "рабочих", "рабочим", "рабочие"}
+	otherWords    = []string{"other", "через"}
+	weekdayWords  = []string{"weekday", "weekdays", "будни", "будням", "будний", "будние"}
+	weekendWords  = []string{"weekend", "weekends", "выходные", "выходным", "выходной"}
+	dayUnitsWords = []string{"day", "days", "день", "дня", "дней", "дням"}
+)
+
+// naturalRule collects what the words of a phrase tell about the rule.
+type naturalRule struct {
+	unit      RuleKind
+	hasUnit   bool
+	interval  int
+	weekdays  []int
+	ordinals  []NthWeekday
+	monthDays []int
+	months    []int
+	dates     []MonthDay
+}
+
+// ParseNatural turns a Russian or English phrase into the repeat grammar,
+// e.g. "каждый понедельник" into "w 1" and "every other week" into "d 14".
+func ParseNatural(text string) (string, error) {
+	words := naturalWords(text)
+	if len(words) == 0 {
+		return "", fmt.Errorf("text cannot be empty")
+	}
+
+	var nr naturalRule
+	// day numbers and month names by their word index, to pair them into dates
+	numbers := map[int]int{}
+	months := map[int]int{}
+
+	other := false
+	ordinal := 0
+	working := false
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		next := ""
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+
+		// weekdays are working days, so "по будням" and "every weekday" follow
+		// the calendar the same way "каждый рабочий день" does
+		if slices.Contains(weekdayWords, word) {
+			working = true
+			if slices.Contains(dayUnitsWords, next) {
+				continue
+			}
+			word = "day"
+		}
+
+		if unit, ok := naturalUnits[word]; ok {
+			if working && unit == RuleDaily {
+				unit = RuleBusinessDays
+			}
+			// "последний день" is a day of month, "вторую неделю" is an interval
+			switch {
+			case ordinal != 0 && unit == RuleDaily:
+				nr.monthDays = append(nr.monthDays, ordinal)
+				ordinal = 0
+				continue
+			case ordinal > 0:
+				nr.interval = ordinal
+				ordinal = 0
+			case ordinal < 0:
+				return "", fmt.Errorf("cannot recognize %q", text)
+			case other:
+				nr.interval = 2
+				other = false
+			}
+			if nr.hasUnit && nr.unit != unit {
+				return "", fmt.Errorf("conflicting repeat periods in %q", text)
+			}
+			nr.unit, nr.hasUnit = unit, true
+			working = false
+			continue
+		}
+
+		if n, ordinalSuffix, ok := naturalNumber(word); ok {
+			_, unitNext := naturalUnits[next]
+			switch {
+			case naturalWeekday(next) > 0 || ordinalSuffix && slices.Contains(dayUnitsWords, next):
+				ordinal = n
+			case unitNext || slices.Contains(workingWords, next) || slices.Contains(weekdayWords, next):
+				if n < 1 {
+					return "", fmt.Errorf("repeat interval should be positive in %q", text)
+				}
+				nr.interval = n
+			default:
+				numbers[i] = n
+			}
+			continue
+		}
+
+		if n, ok := naturalOrdinals[word]; ok {
+			if next == "to" && i+2 < len(words) && words[i+2] == "last" {
+				n = -n
+				i += 2
+			}
+			ordinal = n
+			continue
+		}
+
+		if weekday := naturalWeekday(word); weekday > 0 {
+			switch {
+			case ordinal != 0:
+				nr.ordinals = append(nr.ordinals, NthWeekday{N: ordinal, Weekday: weekday})
+				ordinal = 0
+			case other:
+				nr.interval = 2
+				other = false
+				nr.weekdays = append(nr.weekdays, weekday)
+			default:
+				nr.weekdays = append(nr.weekdays, weekday)
+			}
+			continue
+		}
+
+		if month := naturalMonth(word); month > 0 {
+			months[i] = month
+			continue
+		}
+
+		switch {
+		case slices.Contains(workingWords, word):
+			working = true
+		case slices.Contains(otherWords, word):
+			other = true
+		case slices.Contains(weekendWords, word):
+			nr.weekdays = append(nr.weekdays, 6, 7)
+		case naturalFillers[word]:
+		default:
+			return "", fmt.Errorf("unknown word %q", word)
+		}
+	}
+
+	if ordinal != 0 || working || other {
+		return "", fmt.Errorf("cannot recognize %q", text)
+	}
+
+	nr.pairDates(words, numbers, months)
+
+	rule, err := nr.rule()
+	if err != nil {
+		return "", err
+	}
+
+	// the result has to be a valid rule in the canonical form
+	rule, err = ParseRepeat(rule.String())
+	if err != nil {
+		return "", fmt.Errorf("cannot recognize %q: %w", text, err)
+	}
+	return rule.String(), nil
+}
+
+// naturalWords returns the lowercase words of the text without punctuation.
+func naturalWords(text string) []string {
+	text = strings.ToLower(strings.ReplaceAll(text, "ё", "е"))
+	text = strings.NewReplacer(",", " ", ";", " ", ".", " ", "!", " ", "?", " ", "(", " ", ")", " ").Replace(text)
+
+	var words []string
+	for _, word := range strings.Fields(text) {
+		if numberPattern.MatchString(word) {
+			words = append(words, word)
+			continue
+		}
+		for _, part := range strings.Split(word, "-") {
+			if part != "" {
+				words = append(words, part)
+			}
+		}
+	}
+	return words
+}
+
+// naturalNumber parses a number written in digits or words and reports
+// whether it has an ordinal suffix, as in "1st" or "15-го".
+func naturalNumber(word string) (int, bool, bool) {
+	if n, ok := naturalNumbers[word]; ok {
+		return n, false, true
+	}
+	match := numberPattern.FindStringSubmatch(word)
+	if match == nil {
+		return 0, false, false
+	}
+	n, err := strconv.Atoi(match[1])
+	return n, match[2] != "", err == nil
+}
+
+func naturalWeekday(word string) int {
+	if weekday, ok := naturalWeekdays[word]; ok {
+		return weekday
+	}
+	return stemIndex(ruWeekdayStems, word)
+}
+
+func naturalMonth(word string) int {
+	if month, ok := naturalMonths[word]; ok {
+		return month
+	}
+	return stemIndex(ruMonthStems, word)
+}
+
+// stemIndex returns the 1-based index of the stem the word begins with or 0.
+func stemIndex(stems []string, word string) int {
+	for i, stem := range stems {
+		if stem != "" && strings.HasPrefix(word, stem) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// pairDates turns a day number next to a month name into a yearly date, as
+// in "8 марта" or "march 8". When some month has no day, the months limit
+// the month days instead, as in "the 1st of january and july".
+func (nr *naturalRule) pairDates(words []string, numbers, months map[int]int) {
+	var dates []MonthDay
+	used := map[int]bool{}
+	for _, i := range slices.Sorted(maps.Keys(months)) {
+		month := months[i]
+		day := 0
+		for _, j := range []int{i - 1, i + 1} {
+			if n, ok := numbers[j]; ok && !used[j] {
+				day = n
+				used[j] = true
+				break
+			}
+		}
+		if day == 0 && i >= 2 && words[i-1] == "of" {
+			if n, ok := numbers[i-2]; ok && !used[i-2] && len(months) == 1 {
+				day = n
+				used[i-2] = true
+			}
+		}
+		if day == 0 {
+			dates = nil
+			break
+		}
+		dates = append(dates, MonthDay{Month: month, Day: day})
+	}
+
+	if len(dates) == len(months) && len(dates) > 0 {
+		nr.dates = dates
+		for i, n := range numbers {
+			if !used[i] {
+				nr.monthDays = append(nr.monthDays, n)
+			}
+		}
+		return
+	}
+
+	nr.months = slices.Collect(maps.Values(months))
+	nr.monthDays = append(nr.monthDays, slices.Collect(maps.Values(numbers))...)
+}
+
+// rule builds the rule out of the collected words. The short grammar is
+// used when it can express the rule and RRULE otherwise.
+func (nr naturalRule) rule() (Rule, error) {
+	interval := max(nr.interval, 1)
+	slices.Sort(nr.weekdays)
+	slices.Sort(nr.monthDays)
+	slices.Sort(nr.months)
+
+	switch {
+	case len(nr.dates) > 0:
+		if len(nr.monthDays) > 0 || nr.hasWeekdays() || nr.hasUnit && nr.unit != RuleYearly {
+			return Rule{}, fmt.Errorf("yearly dates cannot be combined with other days")
+		}
+		return Rule{Kind: RuleYearly, Interval: 1, Dates: nr.dates}, nil
+
+	case len(nr.ordinals) > 0:
+		monthly := nr.unit == RuleMonthly
+		var nth []NthWeekday
+		for _, o := range nr.ordinals {
+			if o.N < 0 || monthly {
+				nth = append(nth, o)
+				continue
+			}
+			// "каждую вторую пятницу" is every other Friday
+			interval = o.N
+			nr.weekdays = append(nr.weekdays, o.Weekday)
+		}
+		if len(nth) == 0 {
+			return nr.weekly(interval), nil
+		}
+		if len(nth) < len(nr.ordinals) || len(nr.monthDays) > 0 {
+			return Rule{}, fmt.Errorf("weekdays of a month cannot be combined with other days")
+		}
+		if interval > 1 || len(nr.months) > 0 {
+			return Rule{Kind: RuleMonthly, Interval: interval, Weekdays: nr.weekdays, NthWeekdays: nth, Months: nr.months, rfc: true}, nil
+		}
+		return Rule{Kind: RuleWeekly, Interval: 1, Weekdays: nr.weekdays, NthWeekdays: nth}, nil
+
+	case len(nr.monthDays) > 0:
+		if nr.hasUnit && nr.unit != RuleMonthly {
+			return Rule{}, fmt.Errorf("days of month need a monthly rule")
+		}
+		// the short grammar counts from the end of a month up to two days only
+		if nr.hasWeekdays() || interval > 1 || nr.monthDays[0] < -2 {
+			return Rule{Kind: RuleMonthly, Interval: interval, Weekdays: nr.weekdays, MonthDays: nr.monthDays, Months: nr.months, rfc: true}, nil
+		}
+		return Rule{Kind: RuleMonthly, Interval: 1, MonthDays: nr.monthDays, Months: nr.months}, nil
+
+	case nr.hasWeekdays():
+		if nr.hasUnit && nr.unit != RuleWeekly && nr.unit != RuleDaily {
+			return Rule{}, fmt.Errorf("weekdays need a weekly rule")
+		}
+		return nr.weekly(interval), nil
+
+	case !nr.hasUnit:
+		return Rule{}, fmt.Errorf("repeat period is not given")
+
+	case len(nr.months) > 0:
+		return Rule{Kind: nr.unit, Interval: interval, Months: nr.months, rfc: true}, nil
+	}
+
+	switch nr.unit {
+	case RuleDaily:
+		return Rule{Kind: RuleDaily, Interval: interval}, nil
+	case RuleWeekly:
+		return Rule{Kind: RuleDaily, Interval: 7 * interval}, nil
+	case RuleBusinessDays:
+		return Rule{Kind: RuleBusinessDays, Interval: interval}, nil
+	case RuleHourly:
+		return Rule{Kind: RuleHourly, Interval: interval}, nil
+	case RuleYearly:
+		if interval == 1 {
+			return Rule{Kind: RuleYearly, Interval: 1}, nil
+		}
+	}
+	return Rule{Kind: nr.unit, Interval: interval, rfc: true}, nil
+}
+
+func (nr naturalRule) hasWeekdays() bool {
+	return len(nr.weekdays) > 0
+}
+
+func (nr naturalRule) weekly(interval int) Rule {
+	slices.Sort(nr.weekdays)
+	weekdays := slices.Compact(nr.weekdays)
+	if interval > 1 || len(nr.months) > 0 {
+		return Rule{Kind: RuleWeekly, Interval: interval, Weekdays: weekdays, Months: nr.months, rfc: true}
+	}
+	return Rule{Kind: RuleWeekly, Interval: 1, Weekdays: weekdays}
+}
+
+func ParseNaturalHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	repeat, err := ParseNatural(r.URL.Query().Get("text"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("parse natural error:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	description, err := Describe(repeat, requestLang(r))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("describe error:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, map[string]string{
+		"repeat":      repeat,
+		"description": description,
+	})
+}
@@ -1,14 +1,18 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
-	"strings"
 	"time"
 )
 
-const formatDate = "20060102"
+const (
+	formatDate = "20060102"
+	formatTime = "15:04"
+	// moments tasks were done or deleted at
+	formatStamp = formatDate + " 15:04:05"
+)
 
 func NextDate(now time.Time, dstart string, repeat string) (string, error) {
 	if repeat == "" {
@@ -20,35 +24,64 @@ func NextDate(now time.Time, dstart string, repeat string) (string, error) {
 		return "", fmt.Errorf("incorrect start date: %w", err)
 	}
 
-	if repeat == "y" {
-		for {
-			startTime = startTime.AddDate(1, 0, 0)
-			if startTime.After(now) {
-				return startTime.Format(formatDate), nil
-			}
+	rule, err := ParseRepeat(repeat)
+	if err != nil {
+		return "", err
+	}
+
+	next, _, err := nextOccurrence(rule, now, startTime)
+	if err != nil {
+		return "", err
+	}
+	return rule.shifted(next).Format(formatDate), nil
+}
+
+// maxSteps bounds the number of occurrences stepped through to find a single
+// date, so that no rule keeps a request busy.
+const maxSteps = 10000
+
+// nextOccurrence returns the first occurrence of the rule after the day of now
+// and its position in the series, where the start date is the first one.
+// Hourly rules return the first occurrence after the time of now instead.
+func nextOccurrence(rule Rule, now time.Time, start time.Time) (time.Time, int, error) {
+	today := calendarDay(now)
+	if rule.Kind == RuleHourly {
+		today = wallClock(now)
+	}
+	rule = rule.withLeapDate(start)
+
+	date, position := rule.skip(start, today)
+
+	for range maxSteps {
+		if rule.Count > 0 && position >= rule.Count {
+			return time.Time{}, 0, ErrRuleEnded
+		}
+		var err error
+		date, err = rule.Next(date)
+		position++
+		if errors.Is(err, ErrRuleEnded) {
+			return time.Time{}, 0, err
 		}
-	} else if after, ok := strings.CutPrefix(repeat, "d "); ok {
-		daysString := after
-		days, err := strconv.Atoi(daysString)
 		if err != nil {
-			return "", fmt.Errorf("wrong format: %w", err)
+			return time.Time{}, 0, fmt.Errorf("no matching date for rule %q: %w", rule, err)
 		}
-		if days <= 0 || days > 400 {
-			return "", fmt.Errorf("daily interval should be from 1 to 400")
+		if date.After(today) {
+			return date, position, nil
 		}
+	}
+	return time.Time{}, 0, fmt.Errorf("too many steps for rule %q", rule)
+}
 
-		for {
-			startTime = startTime.AddDate(0, 0, days)
-			if startTime.After(now) {
-				return startTime.Format(formatDate), nil
-			}
+// calendarDay returns the date of now as a UTC midnight, the same way dates
+// of tasks are parsed.
+func calendarDay(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
 
-		}
-	} else if repeat == "w" || repeat == "m" {
-		return "", fmt.Errorf("wrong format")
-	} else {
-		return "", fmt.Errorf("unknown format")
-	}
+// wallClock returns now to the minute as a UTC time, the same way dates and
+// times of tasks are parsed.
+func wallClock(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), 0, 0, time.UTC)
 }
 
 func NextDateHandler(w http.ResponseWriter, r *http.Request) {
@@ -65,7 +98,11 @@ func NextDateHandler(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	if nowString == "" {
-		now = time.Now()
+		now, err = requestNow(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	} else {
 		now, err = time.Parse(formatDate, nowString)
 		if err != nil {
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultOccurrences = 10
+	maxOccurrences     = 100
+)
+
+// NextDates returns up to count occurrences after now. When until is not
+// zero, occurrences later than until are not returned. Occurrences of hourly
+// rules come with their time. Fewer dates are returned only when the rule
+// ends, otherwise an occurrence that cannot be found is an error.
+func NextDates(now time.Time, dstart string, repeat string, count int, until time.Time) ([]string, error) {
+	if repeat == "" {
+		return nil, fmt.Errorf("repeat cannot be empty")
+	}
+
+	startTime, err := time.Parse(formatDate, dstart)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect start date: %w", err)
+	}
+
+	rule, err := ParseRepeat(repeat)
+	if err != nil {
+		return nil, err
+	}
+	rule = rule.withLeapDate(startTime)
+
+	layout := formatDate
+	if rule.Kind == RuleHourly {
+		layout = formatDate + " " + formatTime
+	}
+
+	dates := []string{}
+
+	date, position, err := nextOccurrence(rule, now, startTime)
+	if errors.Is(err, ErrRuleEnded) {
+		return dates, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for len(dates) < count {
+		shifted := rule.shifted(date)
+		if !until.IsZero() && shifted.After(until) {
+			break
+		}
+		if rule.Count > 0 && position > rule.Count {
+			break
+		}
+		// occurrences shifted to the same working day make one date
+		if value := shifted.Format(layout); len(dates) == 0 || dates[len(dates)-1] != value {
+			dates = append(dates, value)
+		}
+		date, err = rule.Next(date)
+		if errors.Is(err, ErrRuleEnded) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		position++
+	}
+	return dates, nil
+}
+
+func OccurrencesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	now, err := requestNow(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("current time error:", err)
+		writeJson(w, map[string]string{"error": "current time error"})
+		return
+	}
+	if nowString := query.Get("now"); nowString != "" {
+		now, err = time.Parse(formatDate, nowString)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("wrong now format:", err)
+			writeJson(w, map[string]string{"error": "wrong now format"})
+			return
+		}
+	}
+
+	var until time.Time
+	if untilString := query.Get("until"); untilString != "" {
+		var err error
+		until, err = time.Parse(formatDate, untilString)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("wrong until format:", err)
+			writeJson(w, map[string]string{"error": "wrong until format"})
+			return
+		}
+	}
+
+	count := defaultOccurrences
+	if countString := query.Get("count"); countString != "" {
+		var err error
+		count, err = strconv.Atoi(countString)
+		if err != nil || count < 1 || count > maxOccurrences {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("wrong count:", countString)
+			writeJson(w, map[string]string{"error": fmt.Sprintf("count should be from 1 to %d", maxOccurrences)})
+			return
+		}
+	}
+
+	dates, err := NextDates(now, query.Get("date"), query.Get("repeat"), count, until)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("occurrences error:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, dates)
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"finalProject/pkg/db"
+)
+
+// longest project name in characters
+const maxProjectLength = 128
+
+// projectInbox selects the tasks without a project in /api/tasks.
+const projectInbox = "inbox"
+
+// Project deletion modes: the tasks of a deleted project are moved to the
+// inbox or to the trash.
+const (
+	deleteToInbox = "inbox"
+	deleteCascade = "cascade"
+)
+
+type ProjectsResp struct {
+	Projects []*db.Project `json:"projects"`
+}
+
+// projectName checks the name of a project and trims spaces around it.
+func projectName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("project name cannot be empty")
+	}
+	if utf8.RuneCountInString(name) > maxProjectLength {
+		return "", fmt.Errorf("project name is longer than %d characters", maxProjectLength)
+	}
+	return name, nil
+}
+
+func ProjectHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		AddProjectHandler(w, r)
+	case http.MethodPut:
+		UpdateProjectHandler(w, r)
+	case http.MethodGet:
+		GetProjectHandler(w, r)
+	case http.MethodDelete:
+		DeleteProjectHandler(w, r)
+	default:
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+	}
+}
+
+func GetProjectsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	projects, err := db.Projects()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting projects error:", err)
+		writeJson(w, map[string]string{"error": "getting projects error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, ProjectsResp{
+		Projects: projects,
+	})
+}
+
+func AddProjectHandler(w http.ResponseWriter, r *http.Request) {
+	var project db.Project
+
+	err := json.NewDecoder(r.Body).Decode(&project)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	project.Name, err = projectName(project.Name)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong project name:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	id, err := db.AddProject(&project)
+	if errors.Is(err, db.ErrProjectExists) {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("add project error:", err)
+		writeJson(w, map[string]string{"error": fmt.Sprintf("project %q already exists", project.Name)})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("add project error:", err)
+		writeJson(w, map[string]string{"error": "add project error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	writeJson(w, map[string]any{"id": id})
+}
+
+func UpdateProjectHandler(w http.ResponseWriter, r *http.Request) {
+	var project db.Project
+
+	err := json.NewDecoder(r.Body).Decode(&project)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	if project.ID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id is empty")
+		writeJson(w, map[string]string{"error": "id is empty"})
+		return
+	}
+
+	project.Name, err = projectName(project.Name)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong project name:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	err = db.UpdateProject(&project)
+	if errors.Is(err, db.ErrProjectNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("update project error:", err)
+		writeJson(w, map[string]string{"error": "project not found"})
+		return
+	}
+	if errors.Is(err, db.ErrProjectExists) {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("update project error:", err)
+		writeJson(w, map[string]string{"error": fmt.Sprintf("project %q already exists", project.Name)})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("update project error:", err)
+		writeJson(w, map[string]string{"error": "update project error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
+
+func GetProjectHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	if idString == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id cannot be empty")
+		writeJson(w, map[string]string{"error": "id cannot be empty"})
+		return
+	}
+
+	project, err := db.GetProject(idString)
+	if errors.Is(err, db.ErrProjectNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("project not found:", idString)
+		writeJson(w, map[string]string{"error": "project not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting project error:", err)
+		writeJson(w, map[string]string{"error": "getting project error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, project)
+}
+
+// DeleteProjectHandler deletes a project. Its tasks are moved to the inbox
+// unless the mode parameter asks to move them to the trash.
+func DeleteProjectHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	if idString == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id cannot be empty")
+		writeJson(w, map[string]string{"error": "id cannot be empty"})
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != deleteToInbox && mode != deleteCascade {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong delete mode:", mode)
+		writeJson(w, map[string]string{"error": fmt.Sprintf("mode should be %q or %q", deleteToInbox, deleteCascade)})
+		return
+	}
+
+	now, err := requestNow(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("current time error:", err)
+		writeJson(w, map[string]string{"error": "current time error"})
+		return
+	}
+
+	err = db.DeleteProject(idString, mode == deleteCascade, storedStamp(now))
+	if errors.Is(err, db.ErrProjectNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("delete project error:", err)
+		writeJson(w, map[string]string{"error": "project not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("delete project error:", err)
+		writeJson(w, map[string]string{"error": "delete project error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
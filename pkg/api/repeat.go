@@ -0,0 +1,754 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RuleKind int
+
+const (
+	RuleDaily RuleKind = iota
+	RuleWeekly
+	RuleMonthly
+	RuleYearly
+	RuleCron
+	RuleBusinessDays
+	RuleHourly
+)
+
+// NthWeekday is the N-th weekday of a month, counting from the end when N is
+// negative: {2, 2} is the second Tuesday and {-1, 5} is the last Friday.
+type NthWeekday struct {
+	N       int
+	Weekday int
+}
+
+// MonthDay is a day of a year given by month and day, e.g. {12, 25}.
+type MonthDay struct {
+	Month int
+	Day   int
+}
+
+// LeapPolicy tells where a yearly occurrence on February 29 goes in years
+// that are not leap years. By default "y" just adds a year, so that February
+// 29 turns into March 1 for good.
+type LeapPolicy int
+
+const (
+	LeapDefault LeapPolicy = iota
+	LeapMar1
+	LeapFeb28
+	LeapOnly
+)
+
+var leapPolicies = map[string]LeapPolicy{
+	"mar1":  LeapMar1,
+	"feb28": LeapFeb28,
+	"leap":  LeapOnly,
+}
+
+// Rule is a parsed repeat rule. Occurrences are calendar days that fall into
+// the rule period and match every non-empty list. When a list that picks the
+// day is empty, the day, weekday or month of the previous occurrence is used.
+// Count limits the series to that many occurrences counting the start date.
+// Shift moves occurrences that fall on days off to the next working day.
+// Dates lists days of a yearly rule and Leap applies to those on February 29.
+type Rule struct {
+	Kind        RuleKind
+	Interval    int
+	Weekdays    []int
+	NthWeekdays []NthWeekday
+	MonthDays   []int
+	Months      []int
+	Dates       []MonthDay
+	Leap        LeapPolicy
+	Hours       []int
+	Minutes     []int
+	Until       time.Time
+	Count       int
+	Shift       bool
+
+	rfc  bool
+	cron string
+}
+
+var ErrRuleEnded = errors.New("repeat rule has ended")
+
+// ErrSearchLimit means that no occurrence was found within the search limit,
+// which does not tell that the rule has ended.
+var ErrSearchLimit = errors.New("no occurrence within the search limit")
+
+// the Gregorian calendar repeats every 400 years, while occurrences of rules
+// like "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;BYDAY=MO" can be 40 years apart
+const searchLimitYears = 400
+
+var daysInMonth = [13]int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+func ParseRepeat(repeat string) (Rule, error) {
+	if repeat == "" {
+		return Rule{}, fmt.Errorf("repeat cannot be empty")
+	}
+
+	if base, ok := strings.CutSuffix(strings.TrimSpace(repeat), " "+shiftModifier); ok {
+		rule, err := ParseRepeat(base)
+		if err != nil {
+			return Rule{}, err
+		}
+		rule.Shift = true
+		return rule, nil
+	}
+
+	if isRRule(repeat) {
+		return parseRRule(repeat)
+	}
+
+	fields := strings.Fields(repeat)
+	if len(fields) == 0 {
+		return Rule{}, fmt.Errorf("unknown format")
+	}
+
+	switch fields[0] {
+	case "y":
+		rule := Rule{Kind: RuleYearly, Interval: 1}
+		for i, field := range fields[1:] {
+			if policy, ok := leapPolicies[field]; ok && i == len(fields)-2 {
+				rule.Leap = policy
+				continue
+			}
+			if i > 0 {
+				return Rule{}, fmt.Errorf("wrong format")
+			}
+			dates, err := parseMonthDays(field)
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Dates = dates
+		}
+		return rule, nil
+	case "d":
+		if len(fields) != 2 {
+			return Rule{}, fmt.Errorf("wrong format")
+		}
+		days, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Rule{}, fmt.Errorf("wrong format: %w", err)
+		}
+		if days <= 0 || days > 400 {
+			return Rule{}, fmt.Errorf("daily interval should be from 1 to 400")
+		}
+		return Rule{Kind: RuleDaily, Interval: days}, nil
+	case "h":
+		if len(fields) != 2 {
+			return Rule{}, fmt.Errorf("wrong format")
+		}
+		hours, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Rule{}, fmt.Errorf("wrong format: %w", err)
+		}
+		if hours <= 0 || hours > 400 {
+			return Rule{}, fmt.Errorf("hourly interval should be from 1 to 400")
+		}
+		return Rule{Kind: RuleHourly, Interval: hours}, nil
+	case "bd":
+		if len(fields) != 2 {
+			return Rule{}, fmt.Errorf("wrong format")
+		}
+		days, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return Rule{}, fmt.Errorf("wrong format: %w", err)
+		}
+		if days <= 0 || days > 400 {
+			return Rule{}, fmt.Errorf("business day interval should be from 1 to 400")
+		}
+		return Rule{Kind: RuleBusinessDays, Interval: days}, nil
+	case "w":
+		if len(fields) != 2 {
+			return Rule{}, fmt.Errorf("wrong format")
+		}
+		weekdays, nthWeekdays, err := parseWeekdays(fields[1])
+		if err != nil {
+			return Rule{}, err
+		}
+		return Rule{Kind: RuleWeekly, Interval: 1, Weekdays: weekdays, NthWeekdays: nthWeekdays}, nil
+	case "cron":
+		return parseCron(fields[1:])
+	case "m":
+		if len(fields) != 2 && len(fields) != 3 {
+			return Rule{}, fmt.Errorf("wrong format")
+		}
+		days, err := parseList(fields[1], func(day int) bool {
+			return day >= -2 && day <= 31 && day != 0
+		})
+		if err != nil {
+			return Rule{}, fmt.Errorf("day of month should be from 1 to 31, -1 or -2: %w", err)
+		}
+		rule := Rule{Kind: RuleMonthly, Interval: 1, MonthDays: days}
+		if len(fields) == 3 {
+			rule.Months, err = parseList(fields[2], func(month int) bool {
+				return month >= 1 && month <= 12
+			})
+			if err != nil {
+				return Rule{}, fmt.Errorf("month should be from 1 to 12: %w", err)
+			}
+		}
+		if !rule.canMatch() {
+			return Rule{}, fmt.Errorf("monthly rule never matches")
+		}
+		return rule, nil
+	default:
+		return Rule{}, fmt.Errorf("unknown format")
+	}
+}
+
+// parseList parses a comma-separated list of numbers and returns them sorted
+// and without duplicates.
+func parseList(list string, valid func(int) bool) ([]int, error) {
+	var values []int
+	for _, valueString := range strings.Split(list, ",") {
+		value, err := strconv.Atoi(valueString)
+		if err != nil {
+			return nil, fmt.Errorf("wrong format: %w", err)
+		}
+		if !valid(value) {
+			return nil, fmt.Errorf("value %d is out of range", value)
+		}
+		values = append(values, value)
+	}
+	slices.Sort(values)
+	return slices.Compact(values), nil
+}
+
+// parseWeekdays parses a list of weekdays from 1 to 7 where an item of the
+// form "weekday#n" is the n-th such weekday of a month, e.g. "2#2" or "5#-1".
+func parseWeekdays(list string) ([]int, []NthWeekday, error) {
+	var plain []string
+	var nthWeekdays []NthWeekday
+
+	for _, item := range strings.Split(list, ",") {
+		weekdayString, nString, ok := strings.Cut(item, "#")
+		if !ok {
+			plain = append(plain, item)
+			continue
+		}
+		weekday, err := strconv.Atoi(weekdayString)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wrong format: %w", err)
+		}
+		n, err := strconv.Atoi(nString)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wrong format: %w", err)
+		}
+		if weekday < 1 || weekday > 7 {
+			return nil, nil, fmt.Errorf("day of week should be from 1 to 7")
+		}
+		if n == 0 || n < -5 || n > 5 {
+			return nil, nil, fmt.Errorf("weekday position should be from 1 to 5 or from -5 to -1")
+		}
+		nthWeekdays = append(nthWeekdays, NthWeekday{N: n, Weekday: weekday})
+	}
+
+	var weekdays []int
+	if len(plain) > 0 {
+		var err error
+		weekdays, err = parseList(strings.Join(plain, ","), func(day int) bool {
+			return day >= 1 && day <= 7
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("day of week should be from 1 to 7: %w", err)
+		}
+	}
+
+	return weekdays, sortNthWeekdays(nthWeekdays), nil
+}
+
+// parseMonthDays parses a comma-separated list of days in the MMDD format.
+func parseMonthDays(list string) ([]MonthDay, error) {
+	var dates []MonthDay
+	for _, item := range strings.Split(list, ",") {
+		if len(item) != 4 {
+			return nil, fmt.Errorf("yearly date should be in the MMDD format")
+		}
+		month, err := strconv.Atoi(item[:2])
+		if err != nil {
+			return nil, fmt.Errorf("wrong format: %w", err)
+		}
+		day, err := strconv.Atoi(item[2:])
+		if err != nil {
+			return nil, fmt.Errorf("wrong format: %w", err)
+		}
+		if month < 1 || month > 12 || day < 1 || day > daysInMonth[month] {
+			return nil, fmt.Errorf("wrong yearly date %s", item)
+		}
+		dates = append(dates, MonthDay{Month: month, Day: day})
+	}
+
+	slices.SortFunc(dates, func(a, b MonthDay) int {
+		if a.Month != b.Month {
+			return a.Month - b.Month
+		}
+		return a.Day - b.Day
+	})
+	return slices.Compact(dates), nil
+}
+
+func sortNthWeekdays(nthWeekdays []NthWeekday) []NthWeekday {
+	slices.SortFunc(nthWeekdays, func(a, b NthWeekday) int {
+		if a.Weekday != b.Weekday {
+			return a.Weekday - b.Weekday
+		}
+		return a.N - b.N
+	})
+	return slices.Compact(nthWeekdays)
+}
+
+// canMatch reports whether the month days fit into at least one of the months.
+func (r Rule) canMatch() bool {
+	if len(r.MonthDays) == 0 {
+		return true
+	}
+	months := r.Months
+	if len(months) == 0 {
+		months = []int{1, 3, 5, 7, 8, 10, 12}
+	}
+	for _, month := range months {
+		for _, day := range r.MonthDays {
+			if day <= daysInMonth[month] && -day <= daysInMonth[month] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Next returns the first occurrence strictly after the given date, which is
+// expected to be an occurrence itself or the start date. It returns
+// ErrRuleEnded when there are no occurrences until the end of the rule and
+// ErrSearchLimit when none is found within the search limit.
+func (r Rule) Next(after time.Time) (time.Time, error) {
+	next := r.next(after)
+	if next.IsZero() {
+		// the whole rule up to its end has been searched
+		if !r.Until.IsZero() && r.Until.Before(after.AddDate(searchLimitYears, 0, 0)) {
+			return time.Time{}, ErrRuleEnded
+		}
+		return time.Time{}, ErrSearchLimit
+	}
+	if !r.Until.IsZero() && next.After(r.Until) {
+		return time.Time{}, ErrRuleEnded
+	}
+	return next, nil
+}
+
+func (r Rule) next(after time.Time) time.Time {
+	interval := max(r.Interval, 1)
+
+	if r.Kind == RuleBusinessDays {
+		return addWorkdays(after, interval)
+	}
+	if r.Kind == RuleHourly {
+		return after.Add(time.Duration(interval) * time.Hour)
+	}
+
+	if !r.filtered() {
+		switch r.Kind {
+		case RuleDaily:
+			return after.AddDate(0, 0, interval)
+		case RuleWeekly:
+			return after.AddDate(0, 0, 7*interval)
+		case RuleYearly:
+			if !r.rfc {
+				return after.AddDate(interval, 0, 0)
+			}
+		}
+	}
+
+	// only the days of every interval-th period are checked
+	limit := after.AddDate(searchLimitYears*interval, 0, 0)
+	for date := after.AddDate(0, 0, 1); date.Before(limit); {
+		if periods := r.periodsBetween(after, date) % interval; periods != 0 {
+			date = r.periodStart(date, interval-periods)
+			continue
+		}
+		if r.matches(after, date) {
+			return date
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return time.Time{}
+}
+
+// periodStart returns the first day of the rule period that comes the given
+// number of periods after the period of date.
+func (r Rule) periodStart(date time.Time, periods int) time.Time {
+	switch r.Kind {
+	case RuleWeekly:
+		return date.AddDate(0, 0, 7*periods-isoWeekday(date)+1)
+	case RuleMonthly:
+		return time.Date(date.Year(), date.Month()+time.Month(periods), 1, 0, 0, 0, 0, time.UTC)
+	case RuleYearly:
+		return time.Date(date.Year()+periods, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return date.AddDate(0, 0, periods)
+}
+
+// fixedStep reports whether every occurrence comes a fixed step after the
+// previous one.
+func (r Rule) fixedStep() bool {
+	if r.filtered() {
+		return false
+	}
+	return r.Kind == RuleDaily || r.Kind == RuleWeekly || r.Kind == RuleHourly || r.Kind == RuleYearly && !r.rfc
+}
+
+// advance returns the date the given number of fixed steps after start, the
+// same as calling next that many times.
+func (r Rule) advance(start time.Time, steps int) time.Time {
+	interval := max(r.Interval, 1)
+	if steps == 0 {
+		return start
+	}
+
+	switch r.Kind {
+	case RuleDaily:
+		return start.AddDate(0, 0, steps*interval)
+	case RuleWeekly:
+		return start.AddDate(0, 0, 7*steps*interval)
+	case RuleHourly:
+		// by seconds, since a Duration covers less than 300 years
+		return time.Unix(start.Unix()+int64(steps*interval)*60*60, 0).UTC()
+	}
+
+	// February 29 turns into March 1 on the first step and stays there
+	first := start.AddDate(interval, 0, 0)
+	return first.AddDate((steps-1)*interval, 0, 0)
+}
+
+// skip returns the date to search the next occurrence after today from and
+// its position in the series. Rules with a fixed step jump right to their
+// last occurrence not later than today. Other rules without a count jump to
+// the last day of an aligned period that keeps the day, weekday and month of
+// start, which is all that next needs from the previous occurrence.
+func (r Rule) skip(start, today time.Time) (time.Time, int) {
+	if !today.After(start) {
+		return start, 1
+	}
+	interval := max(r.Interval, 1)
+
+	if r.fixedStep() {
+		var steps int
+		switch r.Kind {
+		case RuleDaily:
+			steps = (dayNumber(today) - dayNumber(start)) / interval
+		case RuleWeekly:
+			steps = (dayNumber(today) - dayNumber(start)) / (7 * interval)
+		case RuleHourly:
+			steps = int((today.Unix() - start.Unix()) / int64(interval*60*60))
+		default:
+			steps = (today.Year() - start.Year()) / interval
+			if r.advance(start, steps).After(today) {
+				steps--
+			}
+		}
+		return r.advance(start, steps), steps + 1
+	}
+
+	if r.Kind == RuleBusinessDays {
+		steps := workdaysBetween(start, today) / interval
+		return lastWorkday(start, today, steps*interval), steps + 1
+	}
+
+	// the series is counted from start, which takes at most maxSteps
+	if r.Count > 0 {
+		return start, 1
+	}
+	if !r.anchored() {
+		return today, 1
+	}
+
+	periods := r.periodsBetween(start, today) / interval * interval
+	for ; periods > 0; periods -= interval {
+		date, ok := r.addPeriods(start, periods)
+		if ok && !date.After(today) {
+			return date, 1
+		}
+	}
+	return start, 1
+}
+
+// addPeriods returns the date the given number of rule periods after start.
+// Monthly and yearly rules keep the day of month and return false when the
+// month has no such day.
+func (r Rule) addPeriods(start time.Time, periods int) (time.Time, bool) {
+	switch r.Kind {
+	case RuleWeekly:
+		return start.AddDate(0, 0, 7*periods), true
+	case RuleMonthly:
+		date := time.Date(start.Year(), start.Month()+time.Month(periods), start.Day(), 0, 0, 0, 0, time.UTC)
+		return date, date.Day() == start.Day()
+	case RuleYearly:
+		date := time.Date(start.Year()+periods, start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+		return date, date.Day() == start.Day()
+	}
+	return start.AddDate(0, 0, periods), true
+}
+
+func (r Rule) filtered() bool {
+	return r.hasWeekdays() || len(r.MonthDays) > 0 || len(r.Months) > 0 || len(r.Dates) > 0
+}
+
+func (r Rule) hasWeekdays() bool {
+	return len(r.Weekdays) > 0 || len(r.NthWeekdays) > 0
+}
+
+// periodsBetween returns the number of rule periods (days, weeks, months or
+// years) between the two dates.
+func (r Rule) periodsBetween(from, to time.Time) int {
+	switch r.Kind {
+	case RuleWeekly:
+		return weekNumber(to) - weekNumber(from)
+	case RuleMonthly:
+		return (to.Year()*12 + int(to.Month())) - (from.Year()*12 + int(from.Month()))
+	case RuleYearly:
+		return to.Year() - from.Year()
+	}
+	return dayNumber(to) - dayNumber(from)
+}
+
+// dayNumber returns the number of days since 1970-01-01 for a midnight date.
+func dayNumber(date time.Time) int {
+	return int(date.Unix() / (24 * 60 * 60))
+}
+
+// weekNumber returns the number of Monday-based weeks since 1969-12-29.
+func weekNumber(date time.Time) int {
+	days := dayNumber(date) + 3
+	if days < 0 {
+		return (days - 6) / 7
+	}
+	return days / 7
+}
+
+func (r Rule) matches(after, date time.Time) bool {
+	if r.Kind == RuleCron {
+		return r.cronMatches(date)
+	}
+	if len(r.Dates) > 0 {
+		return r.matchesDates(date)
+	}
+
+	if len(r.Months) > 0 && !slices.Contains(r.Months, int(date.Month())) {
+		return false
+	}
+	if len(r.MonthDays) > 0 && !matchesMonthDay(r.MonthDays, date) {
+		return false
+	}
+	if r.hasWeekdays() && !r.matchesWeekday(date) {
+		return false
+	}
+
+	switch r.Kind {
+	case RuleWeekly:
+		if !r.hasWeekdays() {
+			return date.Weekday() == after.Weekday()
+		}
+	case RuleMonthly:
+		if len(r.MonthDays) == 0 && !r.hasWeekdays() {
+			return date.Day() == after.Day()
+		}
+	case RuleYearly:
+		if len(r.MonthDays) == 0 && !r.hasWeekdays() {
+			if len(r.Months) == 0 && date.Month() != after.Month() {
+				return false
+			}
+			return date.Day() == after.Day()
+		}
+	}
+	return true
+}
+
+// matchesDates reports whether the date is one of the yearly dates, moving
+// February 29 according to the leap policy in other years.
+func (r Rule) matchesDates(date time.Time) bool {
+	month, day := int(date.Month()), date.Day()
+	leapYear := time.Date(date.Year(), time.February, 29, 0, 0, 0, 0, time.UTC).Day() == 29
+
+	for _, d := range r.Dates {
+		if d.Month == month && d.Day == day {
+			return true
+		}
+		if d.Month != 2 || d.Day != 29 || leapYear {
+			continue
+		}
+		switch r.Leap {
+		case LeapFeb28:
+			if month == 2 && day == 28 {
+				return true
+			}
+		case LeapDefault, LeapMar1:
+			if month == 3 && day == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withLeapDate makes a yearly rule with a leap policy that starts on
+// February 29 keep that day, since occurrences in other years move away.
+func (r Rule) withLeapDate(start time.Time) Rule {
+	if r.Kind != RuleYearly || r.rfc || len(r.Dates) > 0 || r.Leap == LeapDefault {
+		return r
+	}
+	if start.Month() == time.February && start.Day() == 29 {
+		r.Dates = []MonthDay{{Month: 2, Day: 29}}
+	}
+	return r
+}
+
+// matchesWeekday reports whether the date is one of the weekdays or one of
+// the n-th weekdays of its month.
+func (r Rule) matchesWeekday(date time.Time) bool {
+	weekday := isoWeekday(date)
+	if slices.Contains(r.Weekdays, weekday) {
+		return true
+	}
+
+	lastDay := date.AddDate(0, 1, -date.Day()).Day()
+	for _, nth := range r.NthWeekdays {
+		if nth.Weekday != weekday {
+			continue
+		}
+		if nth.N > 0 && (date.Day()-1)/7+1 == nth.N {
+			return true
+		}
+		if nth.N < 0 && (lastDay-date.Day())/7+1 == -nth.N {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) cronMatches(date time.Time) bool {
+	if len(r.Months) > 0 && !slices.Contains(r.Months, int(date.Month())) {
+		return false
+	}
+	monthDay := len(r.MonthDays) == 0 || matchesMonthDay(r.MonthDays, date)
+	weekday := len(r.Weekdays) == 0 || slices.Contains(r.Weekdays, isoWeekday(date))
+	if len(r.MonthDays) > 0 && len(r.Weekdays) > 0 {
+		return monthDay || weekday
+	}
+	return monthDay && weekday
+}
+
+func matchesMonthDay(days []int, date time.Time) bool {
+	lastDay := date.AddDate(0, 1, -date.Day()).Day()
+	for _, day := range days {
+		if day < 0 {
+			day = lastDay + day + 1
+		}
+		if day == date.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func isoWeekday(date time.Time) int {
+	weekday := int(date.Weekday())
+	if weekday == 0 {
+		return 7
+	}
+	return weekday
+}
+
+// anchored reports whether occurrences depend on the start date.
+func (r Rule) anchored() bool {
+	if r.Interval > 1 {
+		return true
+	}
+	switch r.Kind {
+	case RuleBusinessDays:
+		return true
+	case RuleWeekly:
+		return !r.hasWeekdays()
+	case RuleMonthly, RuleYearly:
+		return len(r.MonthDays) == 0 && !r.hasWeekdays() && len(r.Dates) == 0
+	}
+	return false
+}
+
+// shifted returns the occurrence moved to a working day when the rule asks
+// for it.
+func (r Rule) shifted(date time.Time) time.Time {
+	if !r.Shift {
+		return date
+	}
+	return nextWorkday(date)
+}
+
+func (r Rule) String() string {
+	if r.Shift {
+		return r.baseString() + " " + shiftModifier
+	}
+	return r.baseString()
+}
+
+func (r Rule) baseString() string {
+	if r.rfc {
+		return r.rruleString()
+	}
+
+	switch r.Kind {
+	case RuleDaily:
+		return fmt.Sprintf("d %d", r.Interval)
+	case RuleWeekly:
+		items := []string{}
+		if len(r.Weekdays) > 0 {
+			items = append(items, joinList(r.Weekdays))
+		}
+		for _, nth := range r.NthWeekdays {
+			items = append(items, fmt.Sprintf("%d#%d", nth.Weekday, nth.N))
+		}
+		return "w " + strings.Join(items, ",")
+	case RuleMonthly:
+		if len(r.Months) > 0 {
+			return "m " + joinList(r.MonthDays) + " " + joinList(r.Months)
+		}
+		return "m " + joinList(r.MonthDays)
+	case RuleYearly:
+		items := []string{"y"}
+		if len(r.Dates) > 0 {
+			dates := make([]string, len(r.Dates))
+			for i, d := range r.Dates {
+				dates[i] = fmt.Sprintf("%02d%02d", d.Month, d.Day)
+			}
+			items = append(items, strings.Join(dates, ","))
+		}
+		for name, policy := range leapPolicies {
+			if policy == r.Leap {
+				items = append(items, name)
+			}
+		}
+		return strings.Join(items, " ")
+	case RuleCron:
+		return "cron " + r.cron
+	case RuleBusinessDays:
+		return fmt.Sprintf("bd %d", r.Interval)
+	case RuleHourly:
+		return fmt.Sprintf("h %d", r.Interval)
+	}
+	return ""
+}
+
+func joinList(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Subset of RFC 5545 recurrence rules: FREQ, INTERVAL, BYDAY, BYMONTHDAY,
+// BYMONTH, UNTIL and COUNT. BYDAY ordinals such as 2TU or -1FR count within a
+// month. WKST is accepted for Monday only.
+
+var freqKinds = map[string]RuleKind{
+	"DAILY":   RuleDaily,
+	"WEEKLY":  RuleWeekly,
+	"MONTHLY": RuleMonthly,
+	"YEARLY":  RuleYearly,
+}
+
+var weekdayCodes = []string{"", "MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+func isRRule(repeat string) bool {
+	return strings.Contains(strings.ToUpper(repeat), "FREQ=")
+}
+
+func parseRRule(repeat string) (Rule, error) {
+	repeat = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(repeat)), "RRULE:")
+
+	rule := Rule{Interval: 1, rfc: true}
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(repeat, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("wrong rrule part %q", part)
+		}
+		if seen[name] {
+			return Rule{}, fmt.Errorf("duplicate rrule part %s", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			kind, ok := freqKinds[value]
+			if !ok {
+				return Rule{}, fmt.Errorf("unsupported rrule frequency %s", value)
+			}
+			rule.Kind = kind
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("wrong rrule interval: %w", err)
+			}
+			if rule.Interval < 1 || rule.Interval > 400 {
+				return Rule{}, fmt.Errorf("rrule interval should be from 1 to 400")
+			}
+		case "BYDAY":
+			rule.Weekdays, rule.NthWeekdays, err = parseWeekdayCodes(value)
+			if err != nil {
+				return Rule{}, err
+			}
+		case "BYMONTHDAY":
+			rule.MonthDays, err = parseList(value, func(day int) bool {
+				return day >= -31 && day <= 31 && day != 0
+			})
+			if err != nil {
+				return Rule{}, fmt.Errorf("rrule month day should be from 1 to 31 or from -31 to -1: %w", err)
+			}
+		case "BYMONTH":
+			rule.Months, err = parseList(value, func(month int) bool {
+				return month >= 1 && month <= 12
+			})
+			if err != nil {
+				return Rule{}, fmt.Errorf("rrule month should be from 1 to 12: %w", err)
+			}
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+			if err != nil {
+				return Rule{}, err
+			}
+		case "WKST":
+			if value != "MO" {
+				return Rule{}, fmt.Errorf("only WKST=MO is supported")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err != nil {
+				return Rule{}, fmt.Errorf("wrong rrule count: %w", err)
+			}
+			if rule.Count < 1 || rule.Count > maxSteps {
+				return Rule{}, fmt.Errorf("rrule count should be from 1 to %d", maxSteps)
+			}
+		default:
+			return Rule{}, fmt.Errorf("unsupported rrule part %s", name)
+		}
+	}
+
+	if seen["UNTIL"] && seen["COUNT"] {
+		return Rule{}, fmt.Errorf("rrule UNTIL and COUNT cannot be used together")
+	}
+	if !seen["FREQ"] {
+		return Rule{}, fmt.Errorf("rrule FREQ is required")
+	}
+	if len(rule.NthWeekdays) > 0 && rule.Kind != RuleMonthly && (rule.Kind != RuleYearly || len(rule.Months) == 0) {
+		return Rule{}, fmt.Errorf("rrule BYDAY ordinals need FREQ=MONTHLY or FREQ=YEARLY with BYMONTH")
+	}
+	if !rule.canMatch() {
+		return Rule{}, fmt.Errorf("rrule never matches")
+	}
+	return rule, nil
+}
+
+func parseWeekdayCodes(value string) ([]int, []NthWeekday, error) {
+	var weekdays []int
+	var nthWeekdays []NthWeekday
+	for _, code := range strings.Split(value, ",") {
+		if len(code) < 2 {
+			return nil, nil, fmt.Errorf("wrong rrule weekday %q", code)
+		}
+		ordinal, weekdayCode := code[:len(code)-2], code[len(code)-2:]
+
+		weekday := slices.Index(weekdayCodes, weekdayCode)
+		if weekday < 1 {
+			return nil, nil, fmt.Errorf("wrong rrule weekday %q", code)
+		}
+		if ordinal == "" {
+			weekdays = append(weekdays, weekday)
+			continue
+		}
+
+		n, err := strconv.Atoi(ordinal)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return nil, nil, fmt.Errorf("wrong rrule weekday ordinal %q", code)
+		}
+		nthWeekdays = append(nthWeekdays, NthWeekday{N: n, Weekday: weekday})
+	}
+	slices.Sort(weekdays)
+	return slices.Compact(weekdays), sortNthWeekdays(nthWeekdays), nil
+}
+
+// parseUntil accepts a date or a date-time; only the date part is used.
+func parseUntil(value string) (time.Time, error) {
+	datePart, _, _ := strings.Cut(value, "T")
+	until, err := time.Parse(formatDate, datePart)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("wrong rrule until: %w", err)
+	}
+	return until, nil
+}
+
+func (r Rule) rruleString() string {
+	parts := []string{}
+	for freq, kind := range freqKinds {
+		if kind == r.Kind {
+			parts = append(parts, "FREQ="+freq)
+		}
+	}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.Months) > 0 {
+		parts = append(parts, "BYMONTH="+joinList(r.Months))
+	}
+	if len(r.MonthDays) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinList(r.MonthDays))
+	}
+	if r.hasWeekdays() {
+		codes := []string{}
+		for _, weekday := range r.Weekdays {
+			codes = append(codes, weekdayCodes[weekday])
+		}
+		for _, nth := range r.NthWeekdays {
+			codes = append(codes, strconv.Itoa(nth.N)+weekdayCodes[nth.Weekday])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.Format(formatDate))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
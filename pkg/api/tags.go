@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"finalProject/pkg/db"
+)
+
+// longest tag name in characters
+const maxTagLength = 64
+
+type TagsResp struct {
+	Tags []*db.Tag `json:"tags"`
+}
+
+// RenameTagReq renames the tag Name to NewName.
+type RenameTagReq struct {
+	Name    string `json:"name"`
+	NewName string `json:"new_name"`
+}
+
+// MergeTagsReq moves the tasks of Tags to the tag Into.
+type MergeTagsReq struct {
+	Tags []string `json:"tags"`
+	Into string   `json:"into"`
+}
+
+// normalizeTag trims and lowercases a tag name, so that "Work" and "work "
+// are the same tag.
+func normalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", fmt.Errorf("tag cannot be empty")
+	}
+	if strings.Contains(name, ",") {
+		return "", fmt.Errorf("tag %q cannot contain commas", name)
+	}
+	if utf8.RuneCountInString(name) > maxTagLength {
+		return "", fmt.Errorf("tag %q is longer than %d characters", name, maxTagLength)
+	}
+	return name, nil
+}
+
+// normalizeTags normalizes the tag names and removes duplicates. Nil stays
+// nil, so that updating a task without tags keeps them.
+func normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	normalized := []string{}
+	for _, tag := range tags {
+		name, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// queryTags reads a comma-separated list of tags from a query parameter.
+func queryTags(value string) ([]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	return normalizeTags(strings.Split(value, ","))
+}
+
+func TagsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		GetTagsHandler(w, r)
+	case http.MethodPut:
+		RenameTagHandler(w, r)
+	default:
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+	}
+}
+
+func GetTagsHandler(w http.ResponseWriter, r *http.Request) {
+	tags, err := db.Tags()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting tags error:", err)
+		writeJson(w, map[string]string{"error": "getting tags error"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, TagsResp{
+		Tags: tags,
+	})
+}
+
+func RenameTagHandler(w http.ResponseWriter, r *http.Request) {
+	var req RenameTagReq
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	name, err := normalizeTag(req.Name)
+	if err == nil {
+		req.NewName, err = normalizeTag(req.NewName)
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong tag:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	err = db.RenameTag(name, req.NewName)
+	if errors.Is(err, db.ErrTagNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("rename tag error:", err)
+		writeJson(w, map[string]string{"error": fmt.Sprintf("tag %q not found", name)})
+		return
+	}
+	if errors.Is(err, db.ErrTagExists) {
+		w.WriteHeader(http.StatusConflict)
+		log.Println("rename tag error:", err)
+		writeJson(w, map[string]string{"error": fmt.Sprintf("tag %q already exists, merge the tags instead", req.NewName)})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("rename tag error:", err)
+		writeJson(w, map[string]string{"error": "rename tag error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
+
+func MergeTagsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req MergeTagsReq
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("deserializing JSON error:", err)
+		writeJson(w, map[string]string{"error": "deserializing JSON error"})
+		return
+	}
+
+	tags, err := normalizeTags(req.Tags)
+	if err == nil {
+		req.Into, err = normalizeTag(req.Into)
+	}
+	if err == nil && len(tags) == 0 {
+		err = fmt.Errorf("tags to merge are not given")
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong tag:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	err = db.MergeTags(tags, req.Into)
+	if errors.Is(err, db.ErrTagNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("merge tags error:", err)
+		writeJson(w, map[string]string{"error": "tag not found"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("merge tags error:", err)
+		writeJson(w, map[string]string{"error": "merge tags error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
@@ -3,15 +3,44 @@ package api
 import (
 	"log"
 	"net/http"
+	"strconv"
 
 	"finalProject/pkg/db"
 )
 
 const limit = 50
 
+// sortPriority asks for tasks of higher priority first within a date.
+const sortPriority = "priority"
+
 func GetTasksHandler(w http.ResponseWriter, r *http.Request) {
 
-	tasks, err := db.Tasks(limit)
+	tags, err := queryTags(r.URL.Query().Get("tags"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("wrong tags:", err)
+		writeJson(w, map[string]string{"error": err.Error()})
+		return
+	}
+
+	project := r.URL.Query().Get("project")
+	filter := db.TasksFilter{
+		Limit:      limit,
+		ByPriority: r.URL.Query().Get("sort") == sortPriority,
+		Tags:       tags,
+		Inbox:      project == projectInbox,
+	}
+	if !filter.Inbox && project != "" {
+		if _, err := strconv.Atoi(project); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			log.Println("wrong project:", project)
+			writeJson(w, map[string]string{"error": "project should be an id or " + projectInbox})
+			return
+		}
+		filter.ProjectID = project
+	}
+
+	tasks, err := db.Tasks(filter)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		log.Println("getting tasks error:", err)
@@ -23,6 +52,17 @@ func GetTasksHandler(w http.ResponseWriter, r *http.Request) {
 		tasks = []*db.Task{}
 	}
 
+	lang := requestLang(r)
+	for _, task := range tasks {
+		if task.Repeat == "" {
+			continue
+		}
+		task.RepeatText, err = Describe(task.Repeat, lang)
+		if err != nil {
+			log.Println("describe error:", err)
+		}
+	}
+
 	w.WriteHeader(http.StatusOK)
 	writeJson(w, TasksResp{
 		Tasks: tasks,
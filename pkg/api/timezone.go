@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// location is the time zone of the server. Today and the current time of a
+// request are taken in this zone unless the request gives its own.
+var location = time.Local
+
+// SetTimeZone sets the time zone of the server by its IANA name.
+func SetTimeZone(name string) error {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("loading time zone error: %w", err)
+	}
+	location = loc
+	return nil
+}
+
+// requestLocation returns the time zone of the user asked for by the tz
+// parameter or the X-Timezone header. The server time zone is the default.
+func requestLocation(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		name = r.Header.Get("X-Timezone")
+	}
+	if name == "" {
+		return location, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("wrong time zone: %w", err)
+	}
+	return loc, nil
+}
+
+// storedStamp formats a moment for the database. Stored moments are in UTC,
+// so that they compare as strings whatever time zone wrote them.
+func storedStamp(t time.Time) string {
+	return t.UTC().Format(formatStamp)
+}
+
+// localStamp shows a stored moment in the time zone loc.
+func localStamp(value string, loc *time.Location) string {
+	t, err := time.ParseInLocation(formatStamp, value, time.UTC)
+	if err != nil {
+		return value
+	}
+	return t.In(loc).Format(formatStamp)
+}
+
+// requestNow returns the current time in the time zone of the request. In
+// debug mode the request may run as of the date in the X-As-Of header.
+func requestNow(r *http.Request) (time.Time, error) {
+	loc, err := requestLocation(r)
+	if err != nil {
+		return time.Time{}, err
+	}
+	now := clock.Now().In(loc)
+	if asOf := r.Header.Get("X-As-Of"); debug && asOf != "" {
+		return parseAsOf(asOf, now)
+	}
+	return now, nil
+}
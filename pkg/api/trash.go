@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"finalProject/pkg/db"
+)
+
+// trashDays is how many days deleted tasks stay in the trash before they are
+// purged, 0 keeps them until they are purged by hand.
+var trashDays = 30
+
+type TrashResp struct {
+	Tasks     []*db.Task `json:"tasks"`
+	PurgeDays int        `json:"purge_days"`
+}
+
+// SetTrashPeriod sets the number of days deleted tasks stay in the trash.
+func SetTrashPeriod(value string) error {
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return fmt.Errorf("trash period should be a number of days, got %q", value)
+	}
+	trashDays = days
+	return nil
+}
+
+// purgeExpired deletes for good the tasks that have been in the trash longer
+// than the trash period. A failure leaves them for the next time.
+func purgeExpired(now time.Time) {
+	if trashDays == 0 {
+		return
+	}
+
+	count, err := db.PurgeTrash(storedStamp(now.AddDate(0, 0, -trashDays)))
+	if err != nil {
+		log.Println("purge trash error:", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("purged %d tasks from the trash", count)
+	}
+}
+
+func TrashHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		GetTrashHandler(w, r)
+	case http.MethodDelete:
+		PurgeTaskHandler(w, r)
+	default:
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+	}
+}
+
+func GetTrashHandler(w http.ResponseWriter, r *http.Request) {
+	now, err := requestNow(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("current time error:", err)
+		writeJson(w, map[string]string{"error": "current time error"})
+		return
+	}
+
+	purgeExpired(now)
+
+	tasks, err := db.Tasks(db.TasksFilter{
+		Limit:   limit,
+		Trashed: true,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("getting trash error:", err)
+		writeJson(w, map[string]string{"error": "getting trash error"})
+		return
+	}
+
+	for _, task := range tasks {
+		task.DeletedAt = localStamp(task.DeletedAt, now.Location())
+	}
+
+	w.WriteHeader(http.StatusOK)
+	writeJson(w, TrashResp{
+		Tasks:     tasks,
+		PurgeDays: trashDays,
+	})
+}
+
+// PurgeTaskHandler deletes a task in the trash for good.
+func PurgeTaskHandler(w http.ResponseWriter, r *http.Request) {
+	idString := r.URL.Query().Get("id")
+	if idString == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id cannot be empty")
+		writeJson(w, map[string]string{"error": "id cannot be empty"})
+		return
+	}
+
+	err := db.PurgeTask(idString)
+	if errors.Is(err, db.ErrNotInTrash) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("purge task error:", err)
+		writeJson(w, map[string]string{"error": "task is not in the trash"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("purge task error:", err)
+		writeJson(w, map[string]string{"error": "purge task error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
+
+func RestoreTaskHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "wrong method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	idString := r.URL.Query().Get("id")
+	if idString == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		log.Println("id cannot be empty")
+		writeJson(w, map[string]string{"error": "id cannot be empty"})
+		return
+	}
+
+	err := db.RestoreTask(idString)
+	if errors.Is(err, db.ErrNotInTrash) {
+		w.WriteHeader(http.StatusNotFound)
+		log.Println("restore task error:", err)
+		writeJson(w, map[string]string{"error": "task is not in the trash"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Println("restore task error:", err)
+		writeJson(w, map[string]string{"error": "restore task error"})
+		return
+	}
+
+	writeJson(w, map[string]any{})
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// CalendarDay overrides the Monday-to-Friday working week for one date:
+// a holiday when Working is false, a working weekend day otherwise.
+type CalendarDay struct {
+	Date    string `json:"date"`
+	Working bool   `json:"working"`
+}
+
+func CalendarDays() ([]*CalendarDay, error) {
+
+	query := `SELECT date, working FROM calendar ORDER BY date ASC`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []*CalendarDay{}
+
+	for rows.Next() {
+		day := &CalendarDay{}
+		err := rows.Scan(&day.Date, &day.Working)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, err
+		}
+		days = append(days, day)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.Printf("iteration error: %v", err)
+		return nil, err
+	}
+
+	return days, nil
+}
+
+func SetCalendarDay(day *CalendarDay) error {
+
+	query := `INSERT OR REPLACE INTO calendar (date, working) VALUES (?, ?)`
+	_, err := db.Exec(query, day.Date, day.Working)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+	return nil
+}
+
+func DeleteCalendarDay(date string) error {
+
+	query := `DELETE FROM calendar WHERE date = ?`
+	res, err := db.Exec(query, date)
+	if err != nil {
+		log.Printf("calendar day delete error %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("check calendar day error %v", err)
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("calendar day not found")
+	}
+	return nil
+}
+
+// LoadCalendar reads calendar days from a text file with one date per line
+// in the YYYYMMDD format, optionally followed by "holiday" (the default) or
+// "workday". Empty lines and lines starting with # are ignored.
+func LoadCalendar(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("calendar file opening error: %w", err)
+	}
+	defer file.Close()
+
+	var days []CalendarDay
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) > 2 {
+			return 0, fmt.Errorf("calendar line %d: too many fields", line)
+		}
+
+		if _, err := time.Parse("20060102", fields[0]); err != nil {
+			return 0, fmt.Errorf("calendar line %d: %w", line, err)
+		}
+
+		day := CalendarDay{Date: fields[0]}
+		if len(fields) == 2 {
+			switch fields[1] {
+			case "holiday":
+			case "workday":
+				day.Working = true
+			default:
+				return 0, fmt.Errorf("calendar line %d: unknown day type %q", line, fields[1])
+			}
+		}
+		days = append(days, day)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("calendar file reading error: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	for _, day := range days {
+		_, err := tx.Exec(`INSERT OR REPLACE INTO calendar (date, working) VALUES (?, ?)`, day.Date, day.Working)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(days), tx.Commit()
+}
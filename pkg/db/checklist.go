@@ -0,0 +1,150 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	ErrChecklistOrder        = errors.New("the order should list every checklist item once")
+)
+
+// ChecklistItem is a step of a task. Items are kept in the order of Position.
+type ChecklistItem struct {
+	ID       string `json:"id"`
+	TaskID   string `json:"task_id"`
+	Title    string `json:"title"`
+	Done     bool   `json:"done"`
+	Position int    `json:"position"`
+}
+
+func ChecklistItems(taskID string) ([]*ChecklistItem, error) {
+
+	query := `SELECT id, task_id, title, done, position FROM checklist WHERE task_id = ? ORDER BY position ASC, id ASC`
+
+	rows, err := db.Query(query, taskID)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*ChecklistItem{}
+
+	for rows.Next() {
+		item := &ChecklistItem{}
+		err := rows.Scan(&item.ID, &item.TaskID, &item.Title, &item.Done, &item.Position)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.Printf("iteration error: %v", err)
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// AddChecklistItem adds the item to the end of the checklist of its task.
+func AddChecklistItem(item *ChecklistItem) (int64, error) {
+
+	query := `INSERT INTO checklist (task_id, title, done, position)
+		VALUES (?, ?, ?, (SELECT COALESCE(MAX(position), 0) + 1 FROM checklist WHERE task_id = ?))`
+	res, err := db.Exec(query, item.TaskID, item.Title, item.Done, item.TaskID)
+	if err != nil {
+		return 0, fmt.Errorf("failed request: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get last ID: %w", err)
+	}
+	return id, nil
+}
+
+// liveItem limits checklist queries to the items of tasks that are not in the trash.
+const liveItem = `task_id IN (SELECT id FROM scheduler WHERE ` + notTrashed + `)`
+
+func SetChecklistItemDone(id string, done bool) error {
+
+	res, err := db.Exec(`UPDATE checklist SET done = ? WHERE id = ? AND `+liveItem, done, id)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows count error: %v", err)
+		return err
+	}
+	if count == 0 {
+		return ErrChecklistItemNotFound
+	}
+	return nil
+}
+
+func DeleteChecklistItem(id string) error {
+
+	res, err := db.Exec(`DELETE FROM checklist WHERE id = ? AND `+liveItem, id)
+	if err != nil {
+		log.Printf("checklist item delete error %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("check checklist item error %v", err)
+		return err
+	}
+	if count == 0 {
+		return ErrChecklistItemNotFound
+	}
+	return nil
+}
+
+// ReorderChecklist puts the items of the task in the order of ids, which
+// should list every item of the checklist once.
+func ReorderChecklist(taskID string, ids []string) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("begin transaction error %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var total int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM checklist WHERE task_id = ?`, taskID).Scan(&total)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+	if total != len(ids) {
+		return ErrChecklistOrder
+	}
+
+	for position, id := range ids {
+		res, err := tx.Exec(`UPDATE checklist SET position = ? WHERE id = ? AND task_id = ?`, position+1, id, taskID)
+		if err != nil {
+			log.Printf("failed request: %v", err)
+			return err
+		}
+		count, err := res.RowsAffected()
+		if err != nil {
+			log.Printf("rows count error: %v", err)
+			return err
+		}
+		if count == 0 {
+			return ErrChecklistItemNotFound
+		}
+	}
+
+	return tx.Commit()
+}
@@ -19,6 +19,70 @@ const schema = `CREATE TABLE scheduler (
 CREATE INDEX date_index ON scheduler (date);
 `
 
+// tables added after the initial schema
+const tablesSchema = `CREATE TABLE IF NOT EXISTS exceptions (
+    task_id INTEGER NOT NULL,
+    date CHAR(8) NOT NULL,
+    moved_to CHAR(8) NOT NULL DEFAULT "",
+    PRIMARY KEY (task_id, date)
+);
+CREATE TABLE IF NOT EXISTS calendar (
+    date CHAR(8) PRIMARY KEY,
+    working INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(128) NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS task_tags (
+    task_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    PRIMARY KEY (task_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS task_tags_tag_index ON task_tags (tag_id);
+CREATE TABLE IF NOT EXISTS checklist (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    title VARCHAR(256) NOT NULL DEFAULT "",
+    done INTEGER NOT NULL DEFAULT 0,
+    position INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS checklist_task_index ON checklist (task_id);
+CREATE TABLE IF NOT EXISTS dependencies (
+    task_id INTEGER NOT NULL,
+    depends_on INTEGER NOT NULL,
+    PRIMARY KEY (task_id, depends_on)
+);
+CREATE INDEX IF NOT EXISTS dependencies_depends_on_index ON dependencies (depends_on);
+CREATE TABLE IF NOT EXISTS history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL,
+    date CHAR(8) NOT NULL DEFAULT "",
+    done_at CHAR(17) NOT NULL DEFAULT "",
+    task TEXT NOT NULL DEFAULT ""
+);
+CREATE INDEX IF NOT EXISTS history_done_at_index ON history (done_at);
+`
+
+// columns added to the scheduler table after the initial schema
+var schedulerColumns = []struct {
+	name       string
+	definition string
+}{
+	{"repeat_until", `CHAR(8) NOT NULL DEFAULT ""`},
+	{"repeat_count", `INTEGER NOT NULL DEFAULT 0`},
+	{"repeat_from", `VARCHAR(16) NOT NULL DEFAULT ""`},
+	{"time", `CHAR(5) NOT NULL DEFAULT ""`},
+	{"duration", `INTEGER NOT NULL DEFAULT 0`},
+	{"priority", `INTEGER NOT NULL DEFAULT 0`},
+	{"project_id", `INTEGER NOT NULL DEFAULT 0`},
+	{"deleted_at", `VARCHAR(17) NOT NULL DEFAULT ""`},
+}
+
 var db *sql.DB
 
 func Init(dbFile string) error {
@@ -54,6 +118,47 @@ func Init(dbFile string) error {
 		log.Println("DB schema already exists")
 	}
 
+	if err := migrate(); err != nil {
+		return fmt.Errorf("db migration error: %w", err)
+	}
+
+	return nil
+}
+
+func migrate() error {
+	if _, err := db.Exec(tablesSchema); err != nil {
+		return err
+	}
+
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('scheduler')`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, column := range schedulerColumns {
+		if existing[column.name] {
+			continue
+		}
+		log.Printf("Adding column %s to scheduler", column.name)
+		query := fmt.Sprintf("ALTER TABLE scheduler ADD COLUMN %s %s", column.name, column.definition)
+		if _, err := db.Exec(query); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,126 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	ErrDependencyCycle    = errors.New("dependency makes a cycle")
+	ErrDependencyNotFound = errors.New("dependency not found")
+)
+
+// blockedColumn tells whether a scheduler row depends on other tasks. The
+// tasks in the trash block nothing.
+const blockedColumn = `EXISTS (SELECT 1 FROM dependencies JOIN scheduler AS blocker ON blocker.id = dependencies.depends_on
+	WHERE dependencies.task_id = scheduler.id AND blocker.` + notTrashed + `)`
+
+// Dependencies lists the tasks a task waits for and the tasks waiting for it,
+// leaving out the tasks in the trash.
+type Dependencies struct {
+	BlockedBy []string `json:"blocked_by"`
+	Blocking  []string `json:"blocking"`
+}
+
+func GetDependencies(taskID string) (*Dependencies, error) {
+	deps := &Dependencies{}
+
+	var err error
+	deps.BlockedBy, err = dependencyIDs(`SELECT depends_on FROM dependencies JOIN scheduler ON scheduler.id = depends_on
+		WHERE task_id = ? AND `+notTrashed+` ORDER BY depends_on ASC`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	deps.Blocking, err = dependencyIDs(`SELECT task_id FROM dependencies JOIN scheduler ON scheduler.id = task_id
+		WHERE depends_on = ? AND `+notTrashed+` ORDER BY task_id ASC`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+func dependencyIDs(query string, taskID string) ([]string, error) {
+
+	rows, err := db.Query(query, taskID)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.Printf("iteration error: %v", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// AddDependency makes the task wait for the task dependsOn. A link that would
+// make the task wait for itself, directly or through other tasks, is refused.
+func AddDependency(taskID string, dependsOn string) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("begin transaction error %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	// the tasks dependsOn waits for, directly or not
+	query := `WITH RECURSIVE chain(id) AS (
+			SELECT CAST(? AS INTEGER)
+			UNION
+			SELECT dependencies.depends_on FROM dependencies JOIN chain ON dependencies.task_id = chain.id
+		)
+		SELECT EXISTS (SELECT 1 FROM chain WHERE id = CAST(? AS INTEGER))`
+
+	var cycle bool
+	err = tx.QueryRow(query, dependsOn, taskID).Scan(&cycle)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+	if cycle {
+		return ErrDependencyCycle
+	}
+
+	_, err = tx.Exec(`INSERT OR IGNORE INTO dependencies (task_id, depends_on) VALUES (?, ?)`, taskID, dependsOn)
+	if err != nil {
+		return fmt.Errorf("failed request: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func DeleteDependency(taskID string, dependsOn string) error {
+
+	res, err := db.Exec(`DELETE FROM dependencies WHERE task_id = ? AND depends_on = ?`, taskID, dependsOn)
+	if err != nil {
+		log.Printf("dependency delete error %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("check dependency error %v", err)
+		return err
+	}
+	if count == 0 {
+		return ErrDependencyNotFound
+	}
+	return nil
+}
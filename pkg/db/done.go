@@ -0,0 +1,98 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Completion describes what doing a task changes. The task done for the last
+// time is deleted, a repeating one moves to its next occurrence.
+type Completion struct {
+	TaskID  string
+	History *HistoryEntry
+	Last    bool
+	// the next occurrence and the date of the series it stands for, which
+	// differ when the occurrence is moved or shifted
+	Next     string
+	NextTime string
+	Original string
+}
+
+// CompleteTask records the completion in the history and applies it to the
+// task in one transaction, so that a failure leaves the task as it was.
+func CompleteTask(c *Completion) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("begin transaction error %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addHistory(tx, c.History); err != nil {
+		log.Println(err)
+		return err
+	}
+
+	if c.Last {
+		count, err := deleteTasks(tx, "id = ?", c.TaskID)
+		if err != nil {
+			log.Println(err)
+			return err
+		}
+		if count == 0 {
+			return ErrTaskNotFound
+		}
+		return tx.Commit()
+	}
+
+	if err := completeOccurrence(tx, c); err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// completeOccurrence moves a repeating task to its next occurrence and
+// decreases the number of remaining occurrences when it is limited.
+func completeOccurrence(tx *sql.Tx, c *Completion) error {
+
+	query := "UPDATE scheduler SET date = ?, time = ?, repeat_count = MAX(repeat_count - 1, 0) WHERE id = ?"
+	res, err := tx.Exec(query, c.Next, c.NextTime, c.TaskID)
+	if err != nil {
+		return fmt.Errorf("task update error: %w", err)
+	}
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check task error: %w", err)
+	}
+	if count == 0 {
+		return ErrTaskNotFound
+	}
+
+	// the tasks waiting for this one can start
+	if _, err := tx.Exec(`DELETE FROM dependencies WHERE depends_on = ?`, c.TaskID); err != nil {
+		return fmt.Errorf("dependency delete error: %w", err)
+	}
+
+	// the steps of a repeating task are done anew for the next occurrence
+	if _, err := tx.Exec(`UPDATE checklist SET done = 0 WHERE task_id = ?`, c.TaskID); err != nil {
+		return fmt.Errorf("checklist reset error: %w", err)
+	}
+
+	// a moved occurrence is kept as an exception, so that the series goes on
+	// from its original date
+	if c.Next != c.Original {
+		query := `INSERT OR REPLACE INTO exceptions (task_id, date, moved_to) VALUES (?, ?, ?)`
+		if _, err := tx.Exec(query, c.TaskID, c.Original, c.Next); err != nil {
+			return fmt.Errorf("exception add error: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM exceptions WHERE task_id = ? AND date < ?`, c.TaskID, c.Original); err != nil {
+		return fmt.Errorf("exception delete error: %w", err)
+	}
+	return nil
+}
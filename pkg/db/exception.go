@@ -0,0 +1,77 @@
+package db
+
+import (
+	"fmt"
+	"log"
+)
+
+// Exception changes a single occurrence of a repeating task: the occurrence
+// on Date is skipped when MovedTo is empty and moved to MovedTo otherwise.
+type Exception struct {
+	TaskID  string `json:"task_id"`
+	Date    string `json:"date"`
+	MovedTo string `json:"moved_to"`
+}
+
+func AddException(exception *Exception) error {
+
+	query := `INSERT OR REPLACE INTO exceptions (task_id, date, moved_to) VALUES (?, ?, ?)`
+	_, err := db.Exec(query, exception.TaskID, exception.Date, exception.MovedTo)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+	return nil
+}
+
+func Exceptions(taskID string) ([]*Exception, error) {
+
+	query := `SELECT task_id, date, moved_to FROM exceptions WHERE task_id = ? ORDER BY date ASC`
+
+	rows, err := db.Query(query, taskID)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	exceptions := []*Exception{}
+
+	for rows.Next() {
+		exception := &Exception{}
+		err := rows.Scan(&exception.TaskID, &exception.Date, &exception.MovedTo)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, err
+		}
+		exceptions = append(exceptions, exception)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.Printf("iteration error: %v", err)
+		return nil, err
+	}
+
+	return exceptions, nil
+}
+
+func DeleteException(taskID string, date string) error {
+
+	query := `DELETE FROM exceptions WHERE task_id = ? AND date = ?`
+	res, err := db.Exec(query, taskID, date)
+	if err != nil {
+		log.Printf("exception delete error %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("check exception error %v", err)
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("exception not found")
+	}
+	return nil
+}
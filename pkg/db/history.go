@@ -0,0 +1,104 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// HistoryEntry records a completed task: Task is the task as it was when it
+// was done, Date is the date it was scheduled for and DoneAt is the time it
+// was actually done, stored in UTC.
+type HistoryEntry struct {
+	ID     string `json:"id"`
+	TaskID string `json:"task_id"`
+	Date   string `json:"date"`
+	DoneAt string `json:"done_at"`
+	Task   *Task  `json:"task"`
+}
+
+func addHistory(tx *sql.Tx, entry *HistoryEntry) error {
+
+	snapshot, err := json.Marshal(entry.Task)
+	if err != nil {
+		return fmt.Errorf("task snapshot error: %w", err)
+	}
+
+	query := `INSERT INTO history (task_id, date, done_at, task) VALUES (?, ?, ?, ?)`
+	_, err = tx.Exec(query, entry.TaskID, entry.Date, entry.DoneAt, string(snapshot))
+	if err != nil {
+		return fmt.Errorf("history add error: %w", err)
+	}
+	return nil
+}
+
+// HistoryFilter selects the history entries done from the moment From up to
+// the moment Before, an empty moment leaves the range open on that side.
+// TaskID keeps the completions of one task.
+type HistoryFilter struct {
+	From   string
+	Before string
+	TaskID string
+	Limit  int
+}
+
+// History returns the completions selected by the filter in the order they
+// were done.
+func History(filter HistoryFilter) ([]*HistoryEntry, error) {
+
+	var where []string
+	var args []any
+	if filter.From != "" {
+		where = append(where, "done_at >= ?")
+		args = append(args, filter.From)
+	}
+	if filter.Before != "" {
+		where = append(where, "done_at < ?")
+		args = append(args, filter.Before)
+	}
+	if filter.TaskID != "" {
+		where = append(where, "task_id = ?")
+		args = append(args, filter.TaskID)
+	}
+
+	query := `SELECT id, task_id, date, done_at, task FROM history`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY done_at ASC, id ASC LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*HistoryEntry{}
+
+	for rows.Next() {
+		entry := &HistoryEntry{}
+		var snapshot string
+		err := rows.Scan(&entry.ID, &entry.TaskID, &entry.Date, &entry.DoneAt, &snapshot)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(snapshot), &entry.Task); err != nil {
+			log.Printf("task snapshot error: %v", err)
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.Printf("iteration error: %v", err)
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+var (
+	ErrProjectNotFound = errors.New("project not found")
+	ErrProjectExists   = errors.New("project already exists")
+)
+
+// Project is a named list of tasks, Count is the number of its tasks. Tasks
+// without a project are in the inbox and have project_id 0.
+type Project struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// projectID turns the project of a task into the value of project_id.
+func projectID(id string) any {
+	if id == "" {
+		return 0
+	}
+	return id
+}
+
+func Projects() ([]*Project, error) {
+
+	query := `SELECT projects.id, name, COUNT(scheduler.id) FROM projects LEFT JOIN scheduler ON scheduler.project_id = projects.id AND scheduler.` + notTrashed + ` GROUP BY projects.id ORDER BY name ASC`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []*Project{}
+
+	for rows.Next() {
+		project := &Project{}
+		err := rows.Scan(&project.ID, &project.Name, &project.Count)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.Printf("iteration error: %v", err)
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func GetProject(id string) (*Project, error) {
+
+	query := `SELECT projects.id, name, COUNT(scheduler.id) FROM projects LEFT JOIN scheduler ON scheduler.project_id = projects.id AND scheduler.` + notTrashed + ` WHERE projects.id = ? GROUP BY projects.id`
+	project := &Project{}
+
+	err := db.QueryRow(query, id).Scan(&project.ID, &project.Name, &project.Count)
+	if err == sql.ErrNoRows {
+		return nil, ErrProjectNotFound
+	}
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// projectNameTaken reports whether another project than id has the name.
+func projectNameTaken(name string, id string) (bool, error) {
+	var taken bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM projects WHERE name = ? AND id != ?)`, name, id).Scan(&taken)
+	return taken, err
+}
+
+func AddProject(project *Project) (int64, error) {
+
+	taken, err := projectNameTaken(project.Name, "")
+	if err != nil {
+		return 0, fmt.Errorf("failed request: %w", err)
+	}
+	if taken {
+		return 0, ErrProjectExists
+	}
+
+	res, err := db.Exec(`INSERT INTO projects (name) VALUES (?)`, project.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed request: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("cannot get last ID: %w", err)
+	}
+	return id, nil
+}
+
+func UpdateProject(project *Project) error {
+
+	taken, err := projectNameTaken(project.Name, project.ID)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+	if taken {
+		return ErrProjectExists
+	}
+
+	res, err := db.Exec(`UPDATE projects SET name = ? WHERE id = ?`, project.Name, project.ID)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows count error: %v", err)
+		return err
+	}
+	if count == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// DeleteProject deletes the project and moves its tasks to the inbox. When
+// cascade is true the tasks are moved to the trash as deleted at deletedAt,
+// so that restored tasks come back to the inbox.
+func DeleteProject(id string, cascade bool, deletedAt string) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("begin transaction error %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM projects WHERE id = ?`, id)
+	if err != nil {
+		log.Printf("project delete error %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("check project error %v", err)
+		return err
+	}
+	if count == 0 {
+		return ErrProjectNotFound
+	}
+
+	if cascade {
+		_, err = tx.Exec(`UPDATE scheduler SET deleted_at = ? WHERE project_id = ? AND `+notTrashed, deletedAt, id)
+		if err != nil {
+			log.Printf("project tasks error %v", err)
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`UPDATE scheduler SET project_id = 0 WHERE project_id = ?`, id)
+	if err != nil {
+		log.Printf("project tasks error %v", err)
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,181 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+// Tag is a label tasks are grouped by, Count is the number of its tasks.
+type Tag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// tagsColumn selects the names of the tags of a scheduler row joined by commas.
+const tagsColumn = `(SELECT GROUP_CONCAT(name) FROM task_tags JOIN tags ON tags.id = task_tags.tag_id WHERE task_tags.task_id = scheduler.id)`
+
+// splitTags turns the tags column back into sorted names.
+func splitTags(column sql.NullString) []string {
+	if !column.Valid || column.String == "" {
+		return nil
+	}
+	tags := strings.Split(column.String, ",")
+	sort.Strings(tags)
+	return tags
+}
+
+func Tags() ([]*Tag, error) {
+
+	query := `SELECT name, COUNT(scheduler.id) FROM tags LEFT JOIN task_tags ON task_tags.tag_id = tags.id
+		LEFT JOIN scheduler ON scheduler.id = task_tags.task_id AND scheduler.` + notTrashed + `
+		GROUP BY tags.id ORDER BY name ASC`
+
+	rows, err := db.Query(query)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*Tag{}
+
+	for rows.Next() {
+		tag := &Tag{}
+		err := rows.Scan(&tag.Name, &tag.Count)
+		if err != nil {
+			log.Printf("scan error: %v", err)
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		log.Printf("iteration error: %v", err)
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// setTaskTags replaces the tags of a task, creating the missing ones.
+func setTaskTags(tx *sql.Tx, taskID any, tags []string) error {
+	_, err := tx.Exec(`DELETE FROM task_tags WHERE task_id = ?`, taskID)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range tags {
+		tagID, err := tagID(tx, name)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`INSERT OR IGNORE INTO task_tags (task_id, tag_id) VALUES (?, ?)`, taskID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tagID returns the id of the tag with the given name, creating it if needed.
+func tagID(tx *sql.Tx, name string) (int64, error) {
+	_, err := tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, name)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = tx.QueryRow(`SELECT id FROM tags WHERE name = ?`, name).Scan(&id)
+	return id, err
+}
+
+// RenameTag gives the tag a new name. Renaming a tag to the name it already
+// has is not a conflict.
+func RenameTag(name string, newName string) error {
+
+	var id int64
+	err := db.QueryRow(`SELECT id FROM tags WHERE name = ?`, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return ErrTagNotFound
+	}
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+
+	var otherID int64
+	err = db.QueryRow(`SELECT id FROM tags WHERE name = ?`, newName).Scan(&otherID)
+	if err == nil && otherID != id {
+		return ErrTagExists
+	}
+	if err != nil && err != sql.ErrNoRows {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+
+	_, err = db.Exec(`UPDATE tags SET name = ? WHERE id = ?`, newName, id)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+	return nil
+}
+
+// MergeTags moves the tasks of the given tags to the tag into and deletes
+// the merged tags. The tag into is created when it does not exist.
+func MergeTags(names []string, into string) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("begin transaction error %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	intoID, err := tagID(tx, into)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+
+	for _, name := range names {
+		if name == into {
+			continue
+		}
+
+		var fromID int64
+		err := tx.QueryRow(`SELECT id FROM tags WHERE name = ?`, name).Scan(&fromID)
+		if err == sql.ErrNoRows {
+			return ErrTagNotFound
+		}
+		if err != nil {
+			log.Printf("failed request: %v", err)
+			return err
+		}
+
+		query := `INSERT OR IGNORE INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags WHERE tag_id = ?`
+		if _, err := tx.Exec(query, intoID, fromID); err != nil {
+			log.Printf("failed request: %v", err)
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM task_tags WHERE tag_id = ?`, fromID); err != nil {
+			log.Printf("task tags delete error %v", err)
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM tags WHERE id = ?`, fromID); err != nil {
+			log.Printf("tag delete error %v", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
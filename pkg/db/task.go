@@ -2,24 +2,57 @@ package db
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	_ "modernc.org/sqlite"
 )
 
+var ErrTaskNotFound = errors.New("task not found")
+
 type Task struct {
-	ID      string `json:"id"`
-	Date    string `json:"date"`
-	Title   string `json:"title"`
-	Comment string `json:"comment"`
-	Repeat  string `json:"repeat"`
+	ID          string   `json:"id"`
+	Date        string   `json:"date"`
+	Title       string   `json:"title"`
+	Comment     string   `json:"comment"`
+	Repeat      string   `json:"repeat"`
+	RepeatUntil string   `json:"repeat_until,omitempty"`
+	RepeatCount int      `json:"repeat_count,omitempty"`
+	RepeatFrom  string   `json:"repeat_from,omitempty"`
+	Time        string   `json:"time,omitempty"`
+	Duration    int      `json:"duration,omitempty"`
+	Priority    int      `json:"priority,omitempty"`
+	ProjectID   string   `json:"project_id,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	RepeatText  string   `json:"repeat_text,omitempty"`
+	// the moment the task was moved to the trash, stored in UTC
+	DeletedAt string `json:"deleted_at,omitempty"`
+	// the task waits for other tasks to be done
+	Blocked bool `json:"blocked,omitempty"`
+	// filled in for a single task only
+	Checklist []*ChecklistItem `json:"checklist,omitempty"`
+	Progress  *Progress        `json:"progress,omitempty"`
+}
+
+// Progress counts the checked items of a checklist.
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
 }
 
 func AddTask(task *Task) (int64, error) {
 	var id int64
-	query := `INSERT INTO scheduler (date, title, comment, repeat) VALUES (?, ?, ?, ?)`
-	res, err := db.Exec(query, task.Date, task.Title, task.Comment, task.Repeat)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO scheduler (date, title, comment, repeat, repeat_until, repeat_count, repeat_from, time, duration, priority, project_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := tx.Exec(query, task.Date, task.Title, task.Comment, task.Repeat, task.RepeatUntil, task.RepeatCount, task.RepeatFrom, task.Time, task.Duration, task.Priority, projectID(task.ProjectID))
 	if err != nil {
 		return 0, fmt.Errorf("failed request: %w", err)
 	}
@@ -27,15 +60,86 @@ func AddTask(task *Task) (int64, error) {
 	if err != nil {
 		return 0, fmt.Errorf("cannot get last ID: %w", err)
 	}
-	return id, nil
+
+	if err := setTaskTags(tx, id, task.Tags); err != nil {
+		return 0, fmt.Errorf("setting tags error: %w", err)
+	}
+	return id, tx.Commit()
+}
+
+// TasksFilter selects the tasks returned by Tasks.
+type TasksFilter struct {
+	Limit int
+	// tasks of higher priority come first within a date
+	ByPriority bool
+	// only tasks that have all of these tags
+	Tags []string
+	// only tasks of this project, or of the inbox when Inbox is true
+	ProjectID string
+	Inbox     bool
+	// the tasks in the trash instead of the others, the last deleted first
+	Trashed bool
 }
 
-func Tasks(limit int) ([]*Task, error) {
+// taskColumns are the columns read by scanTask.
+const taskColumns = `id, date, title, comment, repeat, repeat_until, repeat_count, repeat_from, time, duration, priority, NULLIF(project_id, 0), deleted_at, ` + tagsColumn + `, ` + blockedColumn
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row scanner) (*Task, error) {
+	task := &Task{}
+	var project, tags sql.NullString
+	err := row.Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat, &task.RepeatUntil, &task.RepeatCount, &task.RepeatFrom, &task.Time, &task.Duration, &task.Priority, &project, &task.DeletedAt, &tags, &task.Blocked)
+	if err != nil {
+		return nil, err
+	}
+	task.ProjectID = project.String
+	task.Tags = splitTags(tags)
+	return task, nil
+}
+
+// Tasks returns the nearest tasks that match the filter ordered by date and
+// time.
+func Tasks(filter TasksFilter) ([]*Task, error) {
 
 	db := GetDB()
-	query := `SELECT id, date, title, comment, repeat FROM scheduler ORDER BY date ASC LIMIT ?`
 
-	rows, err := db.Query(query, limit)
+	where := []string{notTrashed}
+	if filter.Trashed {
+		where = []string{inTrash}
+	}
+	var args []any
+	if len(filter.Tags) > 0 {
+		where = append(where, `id IN (SELECT task_id FROM task_tags JOIN tags ON tags.id = task_tags.tag_id
+			WHERE name IN (?`+strings.Repeat(", ?", len(filter.Tags)-1)+`) GROUP BY task_id HAVING COUNT(*) = ?)`)
+		for _, tag := range filter.Tags {
+			args = append(args, tag)
+		}
+		args = append(args, len(filter.Tags))
+	}
+
+	if filter.Inbox {
+		where = append(where, "project_id = 0")
+	} else if filter.ProjectID != "" {
+		where = append(where, "project_id = ?")
+		args = append(args, filter.ProjectID)
+	}
+
+	order := "date ASC, time ASC"
+	if filter.ByPriority {
+		order = "date ASC, priority DESC, time ASC"
+	}
+	if filter.Trashed {
+		order = "deleted_at DESC, id DESC"
+	}
+
+	query := `SELECT ` + taskColumns + ` FROM scheduler WHERE ` + strings.Join(where, " AND ")
+	query += ` ORDER BY ` + order + ` LIMIT ?`
+	args = append(args, filter.Limit)
+
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		log.Printf("failed request: %v", err)
 		return nil, err
@@ -45,8 +149,7 @@ func Tasks(limit int) ([]*Task, error) {
 	tasks := []*Task{}
 
 	for rows.Next() {
-		task := &Task{}
-		err := rows.Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat)
+		task, err := scanTask(rows)
 		if err != nil {
 			log.Printf("scar error: %v", err)
 			return nil, err
@@ -69,10 +172,9 @@ func Tasks(limit int) ([]*Task, error) {
 
 func GetTask(id int) (*Task, error) {
 
-	query := `SELECT id, date, title, comment, repeat FROM scheduler WHERE id = ?`
-	task := &Task{}
+	query := `SELECT ` + taskColumns + ` FROM scheduler WHERE id = ? AND ` + notTrashed
 
-	err := db.QueryRow(query, id).Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat)
+	task, err := scanTask(db.QueryRow(query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("task id=%d not found", id)
@@ -85,11 +187,20 @@ func GetTask(id int) (*Task, error) {
 	return task, nil
 }
 
+// UpdateTask saves the task. Its tags are replaced only when Tags is not nil,
+// so that clients which do not know about tags keep them.
 func UpdateTask(task *Task) error {
 
-	query := `UPDATE scheduler SET date = ?, title = ?, comment = ?, repeat = ? WHERE id = ?`
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("begin transaction error %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE scheduler SET date = ?, title = ?, comment = ?, repeat = ?, repeat_until = ?, repeat_count = ?, repeat_from = ?, time = ?, duration = ?, priority = ?, project_id = ? WHERE id = ? AND ` + notTrashed
 
-	res, err := db.Exec(query, task.Date, task.Title, task.Comment, task.Repeat, task.ID)
+	res, err := tx.Exec(query, task.Date, task.Title, task.Comment, task.Repeat, task.RepeatUntil, task.RepeatCount, task.RepeatFrom, task.Time, task.Duration, task.Priority, projectID(task.ProjectID), task.ID)
 	if err != nil {
 		log.Printf("failed request: %v", err)
 		return err
@@ -107,27 +218,70 @@ func UpdateTask(task *Task) error {
 		return err
 	}
 
-	return nil
+	if task.Tags != nil {
+		if err := setTaskTags(tx, task.ID, task.Tags); err != nil {
+			log.Printf("setting tags error: %v", err)
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
+// DeleteTask deletes the task for good, TrashTask moves it to the trash.
 func DeleteTask(id string) error {
 
-	query := "DELETE FROM scheduler WHERE id = ?"
-	res, err := db.Exec(query, id)
+	tx, err := db.Begin()
 	if err != nil {
-		log.Printf("task delete error %v", err)
+		log.Printf("begin transaction error %v", err)
 		return err
 	}
+	defer tx.Rollback()
 
-	count, err := res.RowsAffected()
+	count, err := deleteTasks(tx, "id = ?", id)
 	if err != nil {
-		log.Printf("check task error %v", err)
+		log.Println(err)
 		return err
 	}
 	if count == 0 {
-		return fmt.Errorf("task not found")
+		return ErrTaskNotFound
 	}
-	return nil
+
+	return tx.Commit()
+}
+
+// taskLinks are the columns of other tables that refer to tasks.
+var taskLinks = []struct {
+	table  string
+	column string
+}{
+	{"exceptions", "task_id"},
+	{"task_tags", "task_id"},
+	{"checklist", "task_id"},
+	{"dependencies", "task_id"},
+	{"dependencies", "depends_on"},
+}
+
+// deleteTasks deletes the tasks that match the condition together with the
+// rows that refer to them and returns the number of deleted tasks.
+func deleteTasks(tx *sql.Tx, condition string, args ...any) (int64, error) {
+	for _, link := range taskLinks {
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s IN (SELECT id FROM scheduler WHERE %s)", link.table, link.column, condition)
+		if _, err := tx.Exec(query, args...); err != nil {
+			return 0, fmt.Errorf("%s delete error: %w", link.table, err)
+		}
+	}
+
+	res, err := tx.Exec("DELETE FROM scheduler WHERE "+condition, args...)
+	if err != nil {
+		return 0, fmt.Errorf("task delete error: %w", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("check task error: %w", err)
+	}
+	return count, nil
 }
 
 func UpdateDate(next string, id string) error {
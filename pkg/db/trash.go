@@ -0,0 +1,97 @@
+package db
+
+import (
+	"errors"
+	"log"
+)
+
+var ErrNotInTrash = errors.New("task is not in the trash")
+
+// Deleted tasks stay in the scheduler table with the moment they were deleted
+// at until they are restored or purged.
+const (
+	notTrashed = `deleted_at = ''`
+	inTrash    = `deleted_at != ''`
+)
+
+// TrashTask moves the task to the trash as deleted at deletedAt. Its tags,
+// checklist and links are kept for restoring it.
+func TrashTask(id string, deletedAt string) error {
+
+	res, err := db.Exec(`UPDATE scheduler SET deleted_at = ? WHERE id = ? AND `+notTrashed, deletedAt, id)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows count error: %v", err)
+		return err
+	}
+	if count == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+func RestoreTask(id string) error {
+
+	res, err := db.Exec(`UPDATE scheduler SET deleted_at = '' WHERE id = ? AND `+inTrash, id)
+	if err != nil {
+		log.Printf("failed request: %v", err)
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		log.Printf("rows count error: %v", err)
+		return err
+	}
+	if count == 0 {
+		return ErrNotInTrash
+	}
+	return nil
+}
+
+// PurgeTask deletes a task in the trash for good.
+func PurgeTask(id string) error {
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("begin transaction error %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	count, err := deleteTasks(tx, "id = ? AND "+inTrash, id)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	if count == 0 {
+		return ErrNotInTrash
+	}
+
+	return tx.Commit()
+}
+
+// PurgeTrash deletes for good the tasks moved to the trash before the moment
+// before and returns their number.
+func PurgeTrash(before string) (int64, error) {
+
+	tx, err := db.Begin()
+	if err != nil {
+		log.Printf("begin transaction error %v", err)
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	count, err := deleteTasks(tx, inTrash+" AND deleted_at < ?", before)
+	if err != nil {
+		log.Println(err)
+		return 0, err
+	}
+
+	return count, tx.Commit()
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// requestAsOf sends a request that runs as of the given date. The server
+// should be started with TODO_DEBUG.
+func requestAsOf(apipath string, values map[string]any, method string, asOf string) ([]byte, error) {
+	var data []byte
+	if len(values) > 0 {
+		var err error
+		data, err = json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequest(method, getURL(apipath), bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-As-Of", asOf)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+func TestAsOf(t *testing.T) {
+	if os.Getenv("TODO_DEBUG") == "" {
+		t.Skip("сервер должен быть запущен с TODO_DEBUG")
+	}
+	db := openDB(t)
+	defer db.Close()
+
+	body, err := requestAsOf("api/nextdate?date=20240125&repeat="+url.QueryEscape("d 3"), nil, http.MethodGet, "20240126")
+	assert.NoError(t, err)
+	assert.Equal(t, "20240128", string(body))
+
+	addAsOf := func(values map[string]any, asOf string) string {
+		body, err := requestAsOf("api/task", values, http.MethodPost, asOf)
+		assert.NoError(t, err)
+		var ret map[string]any
+		assert.NoError(t, json.Unmarshal(body, &ret))
+		assert.NotNil(t, ret["id"])
+		return fmt.Sprint(ret["id"])
+	}
+	getAsOf := func(id string) Task {
+		var task Task
+		err := db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		return task
+	}
+	doneAsOf := func(id string, asOf string) {
+		body, err := requestAsOf("api/task/done?id="+id, nil, http.MethodPost, asOf)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{}`, string(body))
+	}
+
+	id := addAsOf(map[string]any{
+		"date":  "20240120",
+		"title": "Просроченная задача",
+	}, "20240126")
+	assert.Equal(t, "20240126", getAsOf(id).Date)
+	doneAsOf(id, "20240126")
+	notFoundTask(t, id)
+
+	id = addAsOf(map[string]any{
+		"date":   "20240126",
+		"title":  "Зарядка",
+		"repeat": "d 3",
+	}, "20240126")
+	assert.Equal(t, "20240126", getAsOf(id).Date)
+	doneAsOf(id, "20240126")
+	assert.Equal(t, "20240129", getAsOf(id).Date)
+	doneAsOf(id, "20240129")
+	assert.Equal(t, "20240201", getAsOf(id).Date)
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+
+	id = addAsOf(map[string]any{
+		"date":   "20240126",
+		"time":   "08:00",
+		"title":  "Проветрить",
+		"repeat": "h 3",
+	}, "20240126 10:00")
+	task := getAsOf(id)
+	assert.Equal(t, "20240126", task.Date)
+	assert.Equal(t, "11:00", task.Time)
+	doneAsOf(id, "20240126 12:00")
+	task = getAsOf(id)
+	assert.Equal(t, "20240126", task.Date)
+	assert.Equal(t, "14:00", task.Time)
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+
+	// a past repeating task starts from its next date, not from today
+	for repeat, want := range map[string]string{
+		"w 1":                     "20240129",
+		"d 10":                    "20240131",
+		"m 15":                    "20240215",
+		"FREQ=MONTHLY;BYDAY=-1FR": "20240126",
+	} {
+		id = addAsOf(map[string]any{
+			"date":   "20240101",
+			"title":  "Прошлая задача",
+			"repeat": repeat,
+		}, "20240124")
+		assert.Equal(t, want, getAsOf(id).Date, repeat)
+		_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+
+	for _, values := range []map[string]any{
+		{"date": "20240101", "title": "Закончилась", "repeat": "FREQ=DAILY;COUNT=3"},
+		{"date": "20240101", "title": "Закончилась", "repeat": "d 1", "repeat_until": "20240110"},
+	} {
+		body, err = requestAsOf("api/task", values, http.MethodPost, "20240124")
+		assert.NoError(t, err)
+		var ret map[string]any
+		assert.NoError(t, json.Unmarshal(body, &ret))
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для %v", values)
+	}
+
+	body, err = requestAsOf("api/task", map[string]any{"title": "Ошибка"}, http.MethodPost, "26.01.2024")
+	assert.NoError(t, err)
+	var ret map[string]any
+	assert.NoError(t, json.Unmarshal(body, &ret))
+	e, ok := ret["error"]
+	assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для неверной даты")
+}
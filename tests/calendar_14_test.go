@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setCalendarDay(t *testing.T, date string, working bool) {
+	ret, err := postJSON("api/calendar", map[string]any{
+		"date":    date,
+		"working": working,
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
+
+func deleteCalendarDay(t *testing.T, date string) {
+	ret, err := postJSON("api/calendar?date="+date, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
+
+func TestBusinessDays(t *testing.T) {
+	nextDate := func(date, repeat string) string {
+		body, err := getBody(fmt.Sprintf("api/nextdate?now=20300104&date=%s&repeat=%s", date, url.QueryEscape(repeat)))
+		assert.NoError(t, err)
+		return strings.TrimSpace(string(body))
+	}
+
+	assert.Equal(t, "20300107", nextDate("20300104", "bd 1"))
+	assert.Equal(t, "20300109", nextDate("20300104", "bd 3"))
+	assert.Equal(t, "20300107", nextDate("20300101", "w 6 shift"))
+	assert.Equal(t, "20300415", nextDate("20300101", "m 13 4 shift"))
+
+	setCalendarDay(t, "20300107", false)
+	setCalendarDay(t, "20300105", true)
+	assert.Equal(t, "20300105", nextDate("20300104", "bd 1"))
+	assert.Equal(t, "20300108", nextDate("20300104", "bd 2"))
+	assert.Equal(t, "20300108", nextDate("20300101", "w 1 shift"))
+	deleteCalendarDay(t, "20300107")
+	deleteCalendarDay(t, "20300105")
+
+	assert.Equal(t, "20300107", nextDate("20300104", "bd 1"))
+
+	for _, repeat := range []string{"bd", "bd 0", "bd 401", "shift", "ooops shift"} {
+		_, err := time.Parse("20060102", nextDate("20300104", repeat))
+		assert.Error(t, err, "Ожидается ошибка для %q", repeat)
+	}
+}
+
+func TestShiftDone(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	day := func(days int) string {
+		return now.AddDate(0, 0, days).Format(`20060102`)
+	}
+
+	setCalendarDay(t, day(7), false)
+	setCalendarDay(t, day(8), true)
+	setCalendarDay(t, day(14), true)
+	defer func() {
+		deleteCalendarDay(t, day(7))
+		deleteCalendarDay(t, day(8))
+		deleteCalendarDay(t, day(14))
+	}()
+
+	id := addTask(t, task{
+		date:   day(0),
+		title:  "Отчёт",
+		repeat: "d 7 shift",
+	})
+
+	for _, want := range []string{day(8), day(14)} {
+		ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+
+		var task Task
+		err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		assert.Equal(t, want, task.Date)
+	}
+}
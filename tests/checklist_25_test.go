@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type checklistItem struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+type checklistTask struct {
+	Date      string          `json:"date"`
+	Checklist []checklistItem `json:"checklist"`
+	Progress  *struct {
+		Done  int `json:"done"`
+		Total int `json:"total"`
+	} `json:"progress"`
+}
+
+func getChecklistTask(t *testing.T, id string) checklistTask {
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var task checklistTask
+	assert.NoError(t, json.Unmarshal(body, &task))
+	return task
+}
+
+func checklistTitles(task checklistTask) []string {
+	titles := []string{}
+	for _, item := range task.Checklist {
+		titles = append(titles, item.Title)
+	}
+	return titles
+}
+
+func TestChecklist(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	id := postTask(t, map[string]any{
+		"date":   now.Format(`20060102`),
+		"title":  "Подготовить релиз",
+		"repeat": "d 7",
+	})
+
+	assert.Nil(t, getChecklistTask(t, id).Progress)
+
+	items := map[string]string{}
+	for _, title := range []string{"Собрать сборку", "Обновить changelog", "Выложить"} {
+		ret, err := postJSON("api/task/checklist", map[string]any{"task_id": id, "title": title}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotNil(t, ret["id"])
+		items[title] = fmt.Sprint(ret["id"])
+	}
+
+	for _, values := range []map[string]any{
+		{"task_id": id, "title": " "},
+		{"task_id": "100500", "title": "Шаг"},
+	} {
+		ret, err := postJSON("api/task/checklist", values, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"])
+	}
+
+	task := getChecklistTask(t, id)
+	assert.Equal(t, []string{"Собрать сборку", "Обновить changelog", "Выложить"}, checklistTitles(task))
+	if assert.NotNil(t, task.Progress) {
+		assert.Equal(t, 0, task.Progress.Done)
+		assert.Equal(t, 3, task.Progress.Total)
+	}
+
+	ret, err := postJSON("api/task/checklist/reorder", map[string]any{
+		"task_id": id,
+		"ids":     []string{items["Обновить changelog"], items["Собрать сборку"], items["Выложить"]},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{"Обновить changelog", "Собрать сборку", "Выложить"}, checklistTitles(getChecklistTask(t, id)))
+
+	for _, ids := range [][]string{
+		{items["Выложить"]},
+		{items["Выложить"], items["Выложить"], items["Собрать сборку"]},
+		{items["Выложить"], items["Собрать сборку"], "100500"},
+	} {
+		ret, err = postJSON("api/task/checklist/reorder", map[string]any{"task_id": id, "ids": ids}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для порядка %v", ids)
+	}
+
+	for _, title := range []string{"Обновить changelog", "Собрать сборку"} {
+		ret, err = postJSON("api/task/checklist/check?id="+items[title], nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+	ret, err = postJSON("api/task/checklist/uncheck?id="+items["Собрать сборку"], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/checklist/check?id=100500", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	task = getChecklistTask(t, id)
+	if assert.NotNil(t, task.Progress) {
+		assert.Equal(t, 1, task.Progress.Done)
+		assert.Equal(t, 3, task.Progress.Total)
+	}
+
+	body, err := requestJSON("api/task/checklist?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var list struct {
+		Items    []checklistItem `json:"items"`
+		Progress struct {
+			Done int `json:"done"`
+		} `json:"progress"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &list))
+	assert.Len(t, list.Items, 3)
+	assert.Equal(t, 1, list.Progress.Done)
+
+	ret, err = postJSON("api/task/checklist?id="+items["Выложить"], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	// done resets the checklist of a repeating task
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	task = getChecklistTask(t, id)
+	assert.Equal(t, now.AddDate(0, 0, 7).Format(`20060102`), task.Date)
+	assert.Equal(t, []string{"Обновить changelog", "Собрать сборку"}, checklistTitles(task))
+	if assert.NotNil(t, task.Progress) {
+		assert.Equal(t, 0, task.Progress.Done)
+		assert.Equal(t, 2, task.Progress.Total)
+	}
+
+	deleteTask(t, id)
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var left int
+	err = db.Get(&left, `SELECT COUNT(*) FROM checklist WHERE task_id = ?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, left)
+}
@@ -0,0 +1,19 @@
+package tests
+
+import "testing"
+
+func TestCron(t *testing.T) {
+	tbl := []nextDate{
+		{"20240101", "cron 0 9 * * 1-5", "20240129"},
+		{"20240101", "cron 0 9 * * sun", "20240128"},
+		{"20240101", "cron 0 9 * * 0", "20240128"},
+		{"20240101", "cron 30 8 13 * 5", "20240202"},
+		{"20240101", "cron 0 9 1 jan,jul *", "20240701"},
+		{"20240101", "cron */15 9-17/2 */10 * *", "20240131"},
+		{"20240101", "cron 0 9 30 2 *", ""},
+		{"20240101", "cron 0 25 * * *", ""},
+		{"20240101", "cron 0 9 * *", ""},
+		{"20240101", "cron 0 9 * * 5-1", ""},
+	}
+	checkNextDates(t, tbl)
+}
@@ -11,11 +11,19 @@ import (
 )
 
 type Task struct {
-	ID      int64  `db:"id"`
-	Date    string `db:"date"`
-	Title   string `db:"title"`
-	Comment string `db:"comment"`
-	Repeat  string `db:"repeat"`
+	ID          int64  `db:"id"`
+	Date        string `db:"date"`
+	Title       string `db:"title"`
+	Comment     string `db:"comment"`
+	Repeat      string `db:"repeat"`
+	RepeatUntil string `db:"repeat_until"`
+	RepeatCount int    `db:"repeat_count"`
+	RepeatFrom  string `db:"repeat_from"`
+	Time        string `db:"time"`
+	Duration    int    `db:"duration"`
+	Priority    int    `db:"priority"`
+	ProjectID   int64  `db:"project_id"`
+	DeletedAt   string `db:"deleted_at"`
 }
 
 func count(db *sqlx.DB) (int, error) {
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func blockedTasks(t *testing.T) map[string]bool {
+	body, err := requestJSON("api/tasks", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		Tasks []struct {
+			Title   string `json:"title"`
+			Blocked bool   `json:"blocked"`
+		} `json:"tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	blocked := map[string]bool{}
+	for _, task := range resp.Tasks {
+		blocked[task.Title] = task.Blocked
+	}
+	return blocked
+}
+
+func TestDependencies(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+	_, err = db.Exec("DELETE FROM dependencies")
+	assert.NoError(t, err)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	ids := map[string]string{}
+	for _, v := range []struct {
+		title  string
+		repeat string
+	}{
+		{"Спроектировать", ""},
+		{"Написать код", "d 3"},
+		{"Выпустить", ""},
+		{"Купить краску", ""},
+		{"Покрасить забор", ""},
+	} {
+		ids[v.title] = postTask(t, map[string]any{
+			"date":   tomorrow,
+			"title":  v.title,
+			"repeat": v.repeat,
+		})
+	}
+
+	link := func(task, dependsOn string) map[string]any {
+		ret, err := postJSON("api/task/dependencies", map[string]any{
+			"task_id":    ids[task],
+			"depends_on": ids[dependsOn],
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		return ret
+	}
+	assert.Empty(t, link("Написать код", "Спроектировать"))
+	assert.Empty(t, link("Выпустить", "Написать код"))
+	assert.Empty(t, link("Покрасить забор", "Купить краску"))
+
+	// links that close a cycle are refused
+	assert.NotEmpty(t, link("Спроектировать", "Выпустить")["error"])
+	assert.NotEmpty(t, link("Спроектировать", "Спроектировать")["error"])
+	ret, err := postJSON("api/task/dependencies", map[string]any{
+		"task_id":    ids["Выпустить"],
+		"depends_on": "100500",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	assert.Equal(t, map[string]bool{
+		"Спроектировать":  false,
+		"Написать код":    true,
+		"Выпустить":       true,
+		"Купить краску":   false,
+		"Покрасить забор": true,
+	}, blockedTasks(t))
+
+	body, err := requestJSON("api/task/dependencies?id="+ids["Написать код"], nil, http.MethodGet)
+	assert.NoError(t, err)
+	var deps map[string][]string
+	assert.NoError(t, json.Unmarshal(body, &deps))
+	assert.Equal(t, []string{ids["Спроектировать"]}, deps["blocked_by"])
+	assert.Equal(t, []string{ids["Выпустить"]}, deps["blocking"])
+
+	// a blocked task cannot be done
+	ret, err = postJSON("api/task/done?id="+ids["Выпустить"], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task/done?id="+ids["Спроектировать"], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.False(t, blockedTasks(t)["Написать код"])
+
+	// done of a repeating task unblocks the tasks waiting for it
+	ret, err = postJSON("api/task/done?id="+ids["Написать код"], nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.False(t, blockedTasks(t)["Выпустить"])
+
+	assert.Empty(t, link("Покрасить забор", "Выпустить"))
+	ret, err = postJSON("api/task/dependencies?id="+ids["Покрасить забор"]+"&depends_on="+ids["Выпустить"], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/dependencies?id="+ids["Покрасить забор"]+"&depends_on="+ids["Выпустить"], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// a task in the trash blocks nothing, purging it drops its links
+	ret, err = postJSON("api/task?id="+ids["Купить краску"], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.False(t, blockedTasks(t)["Покрасить забор"])
+	ret, err = postJSON("api/trash?id="+ids["Купить краску"], nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var links int
+	err = db.Get(&links, `SELECT COUNT(*) FROM dependencies`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, links)
+
+	_, err = db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribe(t *testing.T) {
+	describe := func(repeat, lang string) map[string]string {
+		body, err := getBody("api/describe?repeat=" + url.QueryEscape(repeat) + "&lang=" + lang)
+		assert.NoError(t, err)
+		var m map[string]string
+		assert.NoError(t, json.Unmarshal(body, &m))
+		return m
+	}
+
+	tbl := []struct {
+		repeat string
+		ru     string
+		en     string
+	}{
+		{"d 1", "каждый день", "every day"},
+		{"d 14", "каждые 14 дней", "every 14 days"},
+		{"d 21", "каждый 21 день", "every 21 days"},
+		{"bd 3", "каждые 3 рабочих дня", "every 3 working days"},
+		{"w 1,3,5", "по понедельникам, средам и пятницам", "every Monday, Wednesday and Friday"},
+		{"w 2#2", "во второй вторник месяца", "on the second Tuesday of the month"},
+		{"m -1", "каждый месяц в последний день", "on the last day of every month"},
+		{"m 1,15", "каждый месяц 1-го и 15-го числа", "on the 1st and the 15th of every month"},
+		{"m -1 2", "в последний день февраля", "on the last day of February"},
+		{"y", "каждый год", "every year"},
+		{"y 0308,1225", "каждый год 8 марта и 25 декабря", "every year on March 8 and December 25"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", "каждые 2 недели по понедельникам и четвергам", "every 2 weeks on Mondays and Thursdays"},
+		{"FREQ=MONTHLY;BYMONTHDAY=13;BYDAY=FR", "каждый месяц 13-го числа, если это пятница", "on the 13th of every month if it is a Friday"},
+		{"FREQ=DAILY;UNTIL=20250301", "каждый день до 1 марта 2025", "every day until March 1, 2025"},
+		{"cron 0 9 * * 1-5", "по понедельникам, вторникам, средам, четвергам и пятницам в 9:00", "every Monday, Tuesday, Wednesday, Thursday and Friday at 9:00"},
+		{"d 7 shift", "каждые 7 дней, с переносом на следующий рабочий день", "every 7 days, shifted to the next working day"},
+	}
+	for _, v := range tbl {
+		assert.Equal(t, v.ru, describe(v.repeat, "ru")["description"], v.repeat)
+		assert.Equal(t, v.en, describe(v.repeat, "en")["description"], v.repeat)
+	}
+
+	for _, query := range [][2]string{{"ooops", "ru"}, {"", "ru"}} {
+		_, ok := describe(query[0], query[1])["error"]
+		assert.True(t, ok, "Ожидается ошибка для %q", query)
+	}
+	assert.Equal(t, "каждый день", describe("d 1", "de")["description"])
+
+	id := addTask(t, task{
+		date:   "20240126",
+		title:  "Полить цветы",
+		repeat: "d 14",
+	})
+	for _, task := range getTasks(t, "") {
+		if task["id"] == id {
+			assert.Equal(t, "каждые 14 дней", task["repeat_text"])
+		}
+	}
+
+	body, err := requestJSON("api/tasks?lang=de", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+	found := false
+	for _, task := range m["tasks"] {
+		if task["id"] == id {
+			found = true
+			assert.Equal(t, "каждые 14 дней", task["repeat_text"])
+		}
+	}
+	assert.True(t, found)
+
+	deleteTask(t, id)
+}
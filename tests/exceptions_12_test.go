@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExceptions(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	day := func(days int) string {
+		return now.AddDate(0, 0, days).Format(`20060102`)
+	}
+	taskDate := func(id string) string {
+		var task Task
+		err := db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		return task.Date
+	}
+
+	id := addTask(t, task{
+		date:   day(0),
+		title:  "Тренировка",
+		repeat: "d 7",
+	})
+
+	ret, err := postJSON("api/task/exceptions", map[string]any{
+		"task_id": id,
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(7), taskDate(id))
+
+	ret, err = postJSON("api/task/exceptions", map[string]any{
+		"task_id":  id,
+		"date":     day(14),
+		"moved_to": day(15),
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	for _, v := range []map[string]any{
+		{"task_id": id, "date": day(10)},
+		{"task_id": id, "date": day(21), "moved_to": "ooops"},
+		{"task_id": "ooops", "date": day(21)},
+	} {
+		m, err := postJSON("api/task/exceptions", v, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для %v", v)
+	}
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(15), taskDate(id))
+
+	ret, err = postJSON("api/task/exceptions?id="+id+"&date="+day(14), nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(14), taskDate(id))
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(21), taskDate(id))
+
+	deleteTask(t, id)
+
+	var count int
+	err = db.Get(&count, `SELECT count(*) FROM exceptions WHERE task_id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type historyEntry struct {
+	TaskID string `json:"task_id"`
+	Date   string `json:"date"`
+	DoneAt string `json:"done_at"`
+	Task   struct {
+		Title  string `json:"title"`
+		Date   string `json:"date"`
+		Repeat string `json:"repeat"`
+	} `json:"task"`
+}
+
+func getHistory(t *testing.T, query string) []historyEntry {
+	body, err := requestJSON("api/history"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		History []historyEntry `json:"history"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	return resp.History
+}
+
+func TestHistory(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM history")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	today := now.Format(`20060102`)
+	tomorrow := now.AddDate(0, 0, 1).Format(`20060102`)
+
+	ids := map[string]string{}
+	for _, v := range []struct {
+		title  string
+		repeat string
+	}{
+		{"Полить цветы", "d 2"},
+		{"Оплатить счёт", ""},
+	} {
+		ids[v.title] = postTask(t, map[string]any{
+			"date":   today,
+			"title":  v.title,
+			"repeat": v.repeat,
+		})
+
+		ret, err := postJSON("api/task/done?id="+ids[v.title], nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+	notFoundTask(t, ids["Оплатить счёт"])
+
+	history := getHistory(t, "?from="+today+"&to="+today)
+	assert.Len(t, history, 2)
+	for i, title := range []string{"Полить цветы", "Оплатить счёт"} {
+		if i >= len(history) {
+			break
+		}
+		entry := history[i]
+		assert.Equal(t, ids[title], entry.TaskID)
+		assert.Equal(t, title, entry.Task.Title)
+		assert.Equal(t, today, entry.Date)
+		assert.Equal(t, today, entry.Task.Date)
+		assert.Regexp(t, `^`+today+` \d\d:\d\d:\d\d$`, entry.DoneAt)
+	}
+
+	// the repeating task has moved on, the history keeps the done occurrence
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, ids["Полить цветы"])
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 2).Format(`20060102`), task.Date)
+
+	history = getHistory(t, "?task_id="+ids["Полить цветы"])
+	assert.Len(t, history, 1)
+	assert.Len(t, getHistory(t, "?from="+tomorrow), 0)
+	assert.Len(t, getHistory(t, ""), 2)
+
+	// the range and the times are in the time zone of the request
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	assert.NoError(t, err)
+	there := time.Now().In(kiritimati).Format(`20060102`)
+	history = getHistory(t, "?tz=Pacific/Kiritimati&from="+there+"&to="+there)
+	assert.Len(t, history, 2)
+	for _, entry := range history {
+		assert.Regexp(t, `^`+there+` \d\d:\d\d:\d\d$`, entry.DoneAt)
+	}
+
+	for _, query := range []string{"?from=2024-01-01", "?to=20241301", "?from=" + tomorrow + "&to=" + today} {
+		body, err := requestJSON("api/history"+query, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var ret map[string]any
+		assert.NoError(t, json.Unmarshal(body, &ret))
+		e, ok := ret["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для %s", query)
+	}
+
+	_, err = postJSON("api/task?id="+ids["Полить цветы"], nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeapPolicy(t *testing.T) {
+	tbl := []nextDate{
+		{"20240229", "y", "20250301"},
+		{"20240229", "y feb28", "20250228"},
+		{"20240229", "y mar1", "20250301"},
+		{"20240229", "y leap", "20280229"},
+		{"20250228", "y 0229 feb28", "20260228"},
+		{"20270228", "y 0229 feb28", "20280229"},
+		{"20270301", "y 0229 mar1", "20280229"},
+		{"20240101", "y 0315,1225", "20240315"},
+		{"20240320", "y 0315,1225", "20241225"},
+		{"20240101", "y 0230", ""},
+		{"20240101", "y 1301", ""},
+		{"20240101", "y 315", ""},
+		{"20240101", "y feb28 0101", ""},
+		{"20240101", "y 0101 ooops", ""},
+	}
+	checkNextDates(t, tbl)
+
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{
+		date:   "20280229",
+		title:  "Годовщина",
+		repeat: "y feb28",
+	})
+	var task Task
+	err := db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "y 0229 feb28", task.Repeat)
+
+	deleteTask(t, id)
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNatural(t *testing.T) {
+	parse := func(text, lang string) map[string]string {
+		body, err := getBody("api/parse?text=" + url.QueryEscape(text) + "&lang=" + lang)
+		assert.NoError(t, err)
+		var m map[string]string
+		assert.NoError(t, json.Unmarshal(body, &m))
+		return m
+	}
+
+	tbl := []struct {
+		text   string
+		repeat string
+	}{
+		{"каждый день", "d 1"},
+		{"каждые 14 дней", "d 14"},
+		{"через день", "d 2"},
+		{"раз в две недели", "d 14"},
+		{"каждый понедельник", "w 1"},
+		{"по понедельникам, средам и пятницам", "w 1,3,5"},
+		{"каждую вторую пятницу", "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR"},
+		{"каждую вторую пятницу месяца", "w 5#2"},
+		{"в последнюю пятницу месяца", "w 5#-1"},
+		{"15-го числа каждого месяца", "m 15"},
+		{"в последний день месяца", "m -1"},
+		{"каждый год 8 марта и 25 декабря", "y 0308,1225"},
+		{"каждый рабочий день", "bd 1"},
+		{"по будням", "bd 1"},
+		{"каждый будний день", "bd 1"},
+		{"every day", "d 1"},
+		{"every other week", "d 14"},
+		{"every monday and friday", "w 1,5"},
+		{"every second tuesday of the month", "w 2#2"},
+		{"every month on the 1st", "m 1"},
+		{"the 1st and 15th of every month", "m 1,15"},
+		{"every 3 months", "FREQ=MONTHLY;INTERVAL=3"},
+		{"every year on march 8", "y 0308"},
+		{"every 3 business days", "bd 3"},
+		{"every weekday", "bd 1"},
+		{"every working day", "bd 1"},
+	}
+	for _, v := range tbl {
+		assert.Equal(t, v.repeat, parse(v.text, "ru")["repeat"], v.text)
+	}
+
+	m := parse("каждые 14 дней", "ru")
+	assert.Equal(t, "каждые 14 дней", m["description"])
+	m = parse("every month on the last day", "en")
+	assert.Equal(t, "m -1", m["repeat"])
+	assert.Equal(t, "on the last day of every month", m["description"])
+
+	for _, text := range []string{"", "ooops", "every", "every 500 days", "каждые 0 дней", "every 0 weeks", "каждое 32 число", "every day every month"} {
+		_, ok := parse(text, "ru")["error"]
+		assert.True(t, ok, "Ожидается ошибка для %q", text)
+	}
+}
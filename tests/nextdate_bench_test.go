@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"finalProject/pkg/api"
+)
+
+var benchNow = time.Date(2024, 1, 26, 0, 0, 0, 0, time.UTC)
+
+var benchCases = []struct {
+	name   string
+	date   string
+	repeat string
+}{
+	{"daily", "16890220", "d 7"},
+	{"daily_one", "16890220", "d 1"},
+	{"yearly", "16890220", "y"},
+	{"yearly_leap", "16880229", "y feb28"},
+	{"weekly", "16890220", "w 1,3,5"},
+	{"monthly", "16890220", "m -1,15"},
+	{"nth_weekday", "16890220", "w 5#-1"},
+	{"business_days", "16890220", "bd 3"},
+	{"rrule_weekly", "16890220", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+	{"rrule_monthly", "16890131", "FREQ=MONTHLY;INTERVAL=5"},
+	{"rrule_yearly", "16890220", "FREQ=YEARLY;INTERVAL=3;BYMONTH=2;BYDAY=-1FR"},
+	{"cron", "16890220", "cron 0 9 * * 1-5"},
+}
+
+func BenchmarkNextDate(b *testing.B) {
+	for _, c := range benchCases {
+		b.Run(c.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := api.NextDate(benchNow, c.date, c.repeat); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// stepNextDate finds the next date by stepping through every occurrence
+// since the start date, the way it was done before the jumps.
+func stepNextDate(now time.Time, dstart string, repeat string) (string, error) {
+	date, err := time.Parse("20060102", dstart)
+	if err != nil {
+		return "", err
+	}
+	rule, err := api.ParseRepeat(repeat)
+	if err != nil {
+		return "", err
+	}
+	if rule.Leap != api.LeapDefault && date.Month() == time.February && date.Day() == 29 {
+		rule.Dates = []api.MonthDay{{Month: 2, Day: 29}}
+	}
+	for !date.After(now) {
+		if date, err = rule.Next(date); err != nil {
+			return "", err
+		}
+	}
+	return date.Format("20060102"), nil
+}
+
+// BenchmarkNextDateStepping is the baseline for BenchmarkNextDate on the same
+// inputs.
+func BenchmarkNextDateStepping(b *testing.B) {
+	for _, c := range benchCases {
+		b.Run(c.name, func(b *testing.B) {
+			want, err := api.NextDate(benchNow, c.date, c.repeat)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if got, err := stepNextDate(benchNow, c.date, c.repeat); err != nil || got != want {
+				b.Fatalf("stepping gives %s, %v, want %s", got, err, want)
+			}
+			for b.Loop() {
+				if _, err := stepNextDate(benchNow, c.date, c.repeat); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
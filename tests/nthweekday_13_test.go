@@ -0,0 +1,21 @@
+package tests
+
+import "testing"
+
+func TestNthWeekday(t *testing.T) {
+	tbl := []nextDate{
+		{"20240101", "w 2#2", "20240213"},
+		{"20240101", "w 5#-1", "20240223"},
+		{"20240101", "w 1#5", "20240129"},
+		{"20240101", "w 5#-1,1", "20240129"},
+		{"20240214", "w 2#2", "20240312"},
+		{"20240101", "w 2#0", ""},
+		{"20240101", "w 2#6", ""},
+		{"20240101", "w 8#1", ""},
+		{"20240101", "FREQ=MONTHLY;BYDAY=2TU", "20240213"},
+		{"20240101", "FREQ=MONTHLY;BYDAY=-1FR", "20240223"},
+		{"20240101", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", "20241128"},
+		{"20240101", "FREQ=WEEKLY;BYDAY=2TU", ""},
+	}
+	checkNextDates(t, tbl)
+}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOccurrences(t *testing.T) {
+	getDates := func(query string) ([]string, map[string]any) {
+		body, err := getBody("api/occurrences?now=20240126&" + query)
+		assert.NoError(t, err)
+		var dates []string
+		if err = json.Unmarshal(body, &dates); err == nil {
+			return dates, nil
+		}
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+		return nil, m
+	}
+
+	dates, _ := getDates("date=20240101&repeat=" + url.QueryEscape("w 1,5") + "&count=5")
+	assert.Equal(t, []string{"20240129", "20240202", "20240205", "20240209", "20240212"}, dates)
+
+	dates, _ = getDates("date=20240101&repeat=" + url.QueryEscape("d 7") + "&until=20240215")
+	assert.Equal(t, []string{"20240129", "20240205", "20240212"}, dates)
+
+	dates, _ = getDates("date=20240126&repeat=y")
+	assert.Len(t, dates, 10)
+
+	dates, _ = getDates("date=20240101&repeat=" + url.QueryEscape("FREQ=DAILY;UNTIL=20240128"))
+	assert.Equal(t, []string{"20240127", "20240128"}, dates)
+
+	dates, _ = getDates("date=20240101&repeat=" + url.QueryEscape("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;BYDAY=MO") + "&count=6")
+	assert.Equal(t, []string{"20440229", "20720229", "21120229", "21400229", "21680229", "21960229"}, dates)
+
+	dates, _ = getDates("date=20240101&repeat=" + url.QueryEscape("w 6,7 shift") + "&count=3")
+	assert.Equal(t, []string{"20240129", "20240205", "20240212"}, dates)
+
+	for _, query := range []string{
+		"date=20240101&repeat=" + url.QueryEscape("d 7") + "&count=0",
+		"date=20240101&repeat=" + url.QueryEscape("d 7") + "&count=1000",
+		"date=20240101&repeat=" + url.QueryEscape("d 7") + "&until=2024",
+		"date=20240101&repeat=ooops",
+		"date=20240101",
+	} {
+		_, m := getDates(query)
+		_, ok := m["error"]
+		assert.True(t, ok, fmt.Sprintf("Ожидается ошибка для %s", query))
+	}
+}
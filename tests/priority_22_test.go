@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriority(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	for _, v := range []struct {
+		title    string
+		time     string
+		priority int
+	}{
+		{"Прочитать почту", "09:00", 0},
+		{"Сдать отчёт", "17:00", 3},
+		{"Обед", "13:00", 1},
+	} {
+		postTask(t, map[string]any{
+			"date":     tomorrow,
+			"title":    v.title,
+			"time":     v.time,
+			"priority": v.priority,
+		})
+	}
+
+	assert.Equal(t, []string{"Прочитать почту", "Обед", "Сдать отчёт"}, taskTitles(t, ""))
+	assert.Equal(t, []string{"Сдать отчёт", "Обед", "Прочитать почту"}, taskTitles(t, "?sort=priority"))
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE title=?`, "Сдать отчёт")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, task.Priority)
+
+	body, err := requestJSON(fmt.Sprintf("api/task?id=%d", task.ID), nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(body, &m))
+	assert.Equal(t, float64(3), m["priority"])
+
+	ret, err := postJSON("api/task", map[string]any{
+		"id":       fmt.Sprint(task.ID),
+		"date":     tomorrow,
+		"title":    task.Title,
+		"priority": 2,
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, task.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, task.Priority)
+
+	for _, priority := range []int{-1, 4} {
+		for _, method := range []string{http.MethodPost, http.MethodPut} {
+			ret, err := postJSON("api/task", map[string]any{
+				"id":       fmt.Sprint(task.ID),
+				"date":     tomorrow,
+				"title":    task.Title,
+				"priority": priority,
+			}, method)
+			assert.NoError(t, err)
+			e, ok := ret["error"]
+			assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для приоритета %d", priority)
+		}
+	}
+
+	_, err = db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func addProject(t *testing.T, name string) string {
+	ret, err := postJSON("api/project", map[string]any{"name": name}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotNil(t, ret["id"])
+	return fmt.Sprint(ret["id"])
+}
+
+func TestProjects(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+	_, err = db.Exec("DELETE FROM projects")
+	assert.NoError(t, err)
+
+	sprint := addProject(t, "Спринт")
+	home := addProject(t, " Дом ")
+
+	ret, err := postJSON("api/project", map[string]any{"name": "Спринт"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/project", map[string]any{"name": "  "}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	ids := map[string]string{}
+	for _, v := range []struct {
+		title   string
+		project string
+	}{
+		{"Ревью", sprint},
+		{"Релиз", sprint},
+		{"Полить цветы", home},
+		{"Позвонить маме", ""},
+	} {
+		ids[v.title] = postTask(t, map[string]any{
+			"date":       tomorrow,
+			"title":      v.title,
+			"project_id": v.project,
+		})
+	}
+
+	ret, err = postJSON("api/task", map[string]any{
+		"date":       tomorrow,
+		"title":      "Без проекта",
+		"project_id": "100500",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	body, err := requestJSON("api/task?id="+ids["Ревью"], nil, http.MethodGet)
+	assert.NoError(t, err)
+	var task map[string]string
+	assert.NoError(t, json.Unmarshal(body, &task))
+	assert.Equal(t, sprint, task["project_id"])
+
+	assert.ElementsMatch(t, []string{"Ревью", "Релиз"}, taskTitles(t, "?project="+sprint))
+	assert.ElementsMatch(t, []string{"Полить цветы"}, taskTitles(t, "?project="+home))
+	assert.ElementsMatch(t, []string{"Позвонить маме"}, taskTitles(t, "?project=inbox"))
+	assert.Len(t, taskTitles(t, ""), 4)
+	body, err = requestJSON("api/tasks?project=sprint", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var wrong map[string]string
+	assert.NoError(t, json.Unmarshal(body, &wrong))
+	assert.NotEmpty(t, wrong["error"])
+
+	body, err = requestJSON("api/projects", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var projects struct {
+		Projects []map[string]any `json:"projects"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &projects))
+	assert.Len(t, projects.Projects, 2)
+	if len(projects.Projects) == 2 {
+		assert.Equal(t, "Дом", projects.Projects[0]["name"])
+		assert.Equal(t, float64(1), projects.Projects[0]["count"])
+		assert.Equal(t, "Спринт", projects.Projects[1]["name"])
+		assert.Equal(t, float64(2), projects.Projects[1]["count"])
+	}
+
+	ret, err = postJSON("api/project", map[string]any{"id": home, "name": "Хозяйство"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/project?id="+home, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Equal(t, "Хозяйство", ret["name"])
+	ret, err = postJSON("api/project", map[string]any{"id": home, "name": "Спринт"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// moving a task to the inbox and back
+	ret, err = postJSON("api/task", map[string]any{
+		"id":         ids["Полить цветы"],
+		"date":       tomorrow,
+		"title":      "Полить цветы",
+		"project_id": "",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Позвонить маме", "Полить цветы"}, taskTitles(t, "?project=inbox"))
+	ret, err = postJSON("api/task", map[string]any{
+		"id":         ids["Полить цветы"],
+		"date":       tomorrow,
+		"title":      "Полить цветы",
+		"project_id": home,
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	ret, err = postJSON("api/project?id="+home+"&mode=archive", nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/project?id="+home, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Позвонить маме", "Полить цветы"}, taskTitles(t, "?project=inbox"))
+
+	ret, err = postJSON("api/project?id="+sprint+"&mode=cascade", nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Позвонить маме", "Полить цветы"}, taskTitles(t, ""))
+	notFoundTask(t, ids["Ревью"])
+
+	ret, err = postJSON("api/project?id="+sprint, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	_, err = db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+}
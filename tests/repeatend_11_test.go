@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatEnd(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+
+	done := func(id string) {
+		ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+
+	id := postTask(t, map[string]any{
+		"date":         now.Format(`20060102`),
+		"title":        "Три раза",
+		"repeat":       "d 3",
+		"repeat_count": 3,
+	})
+	for i := 0; i < 2; i++ {
+		done(id)
+		var task Task
+		err := db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		assert.Equal(t, 2-i, task.RepeatCount)
+	}
+	done(id)
+	notFoundTask(t, id)
+
+	id = postTask(t, map[string]any{
+		"date":         now.Format(`20060102`),
+		"title":        "До даты",
+		"repeat":       "d 7",
+		"repeat_until": now.AddDate(0, 0, 10).Format(`20060102`),
+	})
+	done(id)
+	var task Task
+	err := db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 7).Format(`20060102`), task.Date)
+	done(id)
+	notFoundTask(t, id)
+
+	id = postTask(t, map[string]any{
+		"date":   now.Format(`20060102`),
+		"title":  "RRULE COUNT",
+		"repeat": "FREQ=DAILY;COUNT=2",
+	})
+	done(id)
+	done(id)
+	notFoundTask(t, id)
+
+	later := now.AddDate(0, 0, 10).Format(`20060102`)
+	yesterday := now.AddDate(0, 0, -1).Format(`20060102`)
+	for _, values := range []map[string]any{
+		{"title": "Без правила", "repeat_count": 2},
+		{"title": "Неверная дата", "repeat": "d 1", "repeat_until": "2030-01-01"},
+		{"title": "Отрицательно", "repeat": "d 1", "repeat_count": -1},
+		{"title": "После конца", "date": later, "repeat": "d 1", "repeat_until": now.AddDate(0, 0, 5).Format(`20060102`)},
+		{"title": "Каждый час после конца", "date": later, "time": "09:00", "repeat": "h 3", "repeat_until": now.Format(`20060102`)},
+		{"title": "Каждый час до вчера", "date": yesterday, "time": "09:00", "repeat": "h 3", "repeat_until": yesterday},
+	} {
+		if _, ok := values["date"]; !ok {
+			values["date"] = now.Format(`20060102`)
+		}
+		m, err := postJSON("api/task", values, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для задачи %v", values)
+	}
+}
+
+func TestPartialUpdate(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	until := time.Now().AddDate(0, 3, 0).Format(`20060102`)
+
+	ret, err := postJSON("api/project", map[string]any{"name": "Частичное обновление"}, http.MethodPost)
+	assert.NoError(t, err)
+	project := fmt.Sprint(ret["id"])
+
+	id := postTask(t, map[string]any{
+		"date":         tomorrow,
+		"title":        "Полить фикус",
+		"repeat":       "d 2",
+		"repeat_until": until,
+		"repeat_count": 5,
+		"repeat_from":  "done",
+		"time":         "09:30",
+		"duration":     15,
+		"priority":     2,
+		"project_id":   project,
+		"tags":         []string{"дом"},
+	})
+
+	// a client that knows only the first fields of a task
+	ret, err = postJSON("api/task", map[string]any{
+		"id":      id,
+		"date":    tomorrow,
+		"title":   "Полить фикус и пальму",
+		"comment": "Отстоянной водой",
+		"repeat":  "d 2",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Полить фикус и пальму", task.Title)
+	assert.Equal(t, "Отстоянной водой", task.Comment)
+	assert.Equal(t, until, task.RepeatUntil)
+	assert.Equal(t, 5, task.RepeatCount)
+	assert.Equal(t, "done", task.RepeatFrom)
+	assert.Equal(t, "09:30", task.Time)
+	assert.Equal(t, 15, task.Duration)
+	assert.Equal(t, 2, task.Priority)
+	assert.Equal(t, project, fmt.Sprint(task.ProjectID))
+
+	var tags int
+	err = db.Get(&tags, `SELECT COUNT(*) FROM task_tags WHERE task_id = ?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tags)
+
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	_, err = postJSON("api/project?id="+project, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatFrom(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+
+	addFromTask := func(repeatFrom string) string {
+		return postTask(t, map[string]any{
+			"date":        now.AddDate(0, 0, 3).Format(`20060102`),
+			"title":       "Полить цветы",
+			"repeat":      "d 5",
+			"repeat_from": repeatFrom,
+		})
+	}
+	doneDate := func(id string) string {
+		ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+
+		var task Task
+		err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		return task.Date
+	}
+
+	id := addFromTask("done")
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var task map[string]string
+	assert.NoError(t, json.Unmarshal(body, &task))
+	assert.Equal(t, "done", task["repeat_from"])
+	assert.Equal(t, now.AddDate(0, 0, 5).Format(`20060102`), doneDate(id))
+	deleteTask(t, id)
+
+	for _, repeatFrom := range []string{"", "schedule"} {
+		id = addFromTask(repeatFrom)
+		assert.Equal(t, now.AddDate(0, 0, 8).Format(`20060102`), doneDate(id))
+		deleteTask(t, id)
+	}
+
+	for _, values := range []map[string]any{
+		{"title": "Неверный режим", "repeat": "d 5", "repeat_from": "ooops"},
+		{"title": "Без правила", "repeat_from": "done"},
+	} {
+		values["date"] = now.Format(`20060102`)
+		m, err := postJSON("api/task", values, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для задачи %v", values)
+	}
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRRule(t *testing.T) {
+	tbl := []nextDate{
+		{"20240101", "FREQ=DAILY;INTERVAL=10", "20240131"},
+		{"20240101", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20251231", "20240129"},
+		{"20240101", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20240120", ""},
+		{"20240125", "RRULE:FREQ=WEEKLY", "20240201"},
+		{"20231231", "FREQ=MONTHLY", "20240131"},
+		{"20240131", "FREQ=MONTHLY", "20240331"},
+		{"20240110", "FREQ=MONTHLY;BYMONTHDAY=-1,15", "20240131"},
+		{"20240229", "FREQ=YEARLY", "20280229"},
+		{"20240101", "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=8", "20240308"},
+		{"20240101", "freq=daily;byday=sa,su", "20240127"},
+		{"20240101", "FREQ=MONTHLY;BYMONTH=2;BYMONTHDAY=30", ""},
+		{"20240101", "FREQ=DAILY;COUNT=3", ""},
+		{"20240101", "FREQ=HOURLY", ""},
+		{"20240101", "INTERVAL=2", ""},
+		{"20240101", " ", ""},
+	}
+	checkNextDates(t, tbl)
+}
+
+func checkNextDates(t *testing.T, tbl []nextDate) {
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
+
+func TestRuleEnd(t *testing.T) {
+	// the next Monday, February 29 is in 2112, after the end of the rule
+	urlPath := "api/nextdate?now=20730101&date=20720229&repeat=" +
+		url.QueryEscape("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;BYDAY=MO;UNTIL=20800101")
+	get, err := getBody(urlPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(get), "ended")
+	assert.NotContains(t, string(get), "search limit")
+}
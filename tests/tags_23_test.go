@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taggedTask struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+}
+
+func getTaggedTasks(t *testing.T, tags string) []taggedTask {
+	body, err := requestJSON("api/tasks?tags="+tags, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		Tasks []taggedTask `json:"tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Tasks
+}
+
+func getTags(t *testing.T) map[string]int {
+	body, err := requestJSON("api/tags", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		Tags []struct {
+			Name  string `json:"name"`
+			Count int    `json:"count"`
+		} `json:"tags"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	tags := map[string]int{}
+	for _, tag := range resp.Tags {
+		tags[tag.Name] = tag.Count
+	}
+	return tags
+}
+
+func TestTags(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+	_, err = db.Exec("DELETE FROM task_tags")
+	assert.NoError(t, err)
+	_, err = db.Exec("DELETE FROM tags")
+	assert.NoError(t, err)
+
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	ids := map[string]string{}
+	for title, tags := range map[string][]string{
+		"Отчёт":          {"Работа", " финансы", "работа"},
+		"Уборка":         {"дом"},
+		"Оплатить счета": {"дом", "финансы"},
+		"Прогулка":       nil,
+	} {
+		ids[title] = postTask(t, map[string]any{
+			"date":  tomorrow,
+			"title": title,
+			"tags":  tags,
+		})
+	}
+
+	body, err := requestJSON("api/task?id="+ids["Отчёт"], nil, http.MethodGet)
+	assert.NoError(t, err)
+	var task taggedTask
+	assert.NoError(t, json.Unmarshal(body, &task))
+	assert.Equal(t, []string{"работа", "финансы"}, task.Tags)
+
+	assert.Len(t, getTaggedTasks(t, ""), 4)
+	assert.ElementsMatch(t, []string{"Оплатить счета", "Отчёт"}, taskTitles(t, "?tags=финансы"))
+	assert.ElementsMatch(t, []string{"Оплатить счета"}, taskTitles(t, "?tags=финансы,Дом"))
+	assert.Empty(t, getTaggedTasks(t, "отпуск"))
+	assert.Equal(t, map[string]int{"дом": 2, "работа": 1, "финансы": 2}, getTags(t))
+
+	// updating a task without tags keeps them, an empty list removes them
+	ret, err := postJSON("api/task", map[string]any{
+		"id":    ids["Уборка"],
+		"date":  tomorrow,
+		"title": "Генеральная уборка",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Генеральная уборка", "Оплатить счета"}, taskTitles(t, "?tags=дом"))
+
+	ret, err = postJSON("api/task", map[string]any{
+		"id":    ids["Уборка"],
+		"date":  tomorrow,
+		"title": "Генеральная уборка",
+		"tags":  []string{},
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Оплатить счета"}, taskTitles(t, "?tags=дом"))
+
+	ret, err = postJSON("api/tags", map[string]any{"name": "работа", "new_name": "Офис"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Отчёт"}, taskTitles(t, "?tags=офис"))
+
+	ret, err = postJSON("api/tags", map[string]any{"name": "офис", "new_name": " Офис"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Отчёт"}, taskTitles(t, "?tags=офис"))
+
+	ret, err = postJSON("api/tags", map[string]any{"name": "офис", "new_name": "дом"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/tags", map[string]any{"name": "отпуск", "new_name": "каникулы"}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/tags/merge", map[string]any{"tags": []string{"офис", "финансы"}, "into": "дела"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, map[string]int{"дела": 2, "дом": 1}, getTags(t))
+	assert.ElementsMatch(t, []string{"Оплатить счета", "Отчёт"}, taskTitles(t, "?tags=дела"))
+
+	ret, err = postJSON("api/tags/merge", map[string]any{"tags": []string{"отпуск"}, "into": "дела"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task", map[string]any{
+		"date":  tomorrow,
+		"title": "Неверный тег",
+		"tags":  []string{"a,b"},
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	for _, id := range ids {
+		deleteTask(t, id)
+	}
+	assert.Equal(t, map[string]int{"дела": 0, "дом": 0}, getTags(t))
+	_, err = db.Exec("DELETE FROM tags")
+	assert.NoError(t, err)
+}
@@ -11,12 +11,17 @@ import (
 )
 
 func addTask(t *testing.T, task task) string {
-	ret, err := postJSON("api/task", map[string]any{
+	return postTask(t, map[string]any{
 		"date":    task.date,
 		"title":   task.title,
 		"comment": task.comment,
 		"repeat":  task.repeat,
-	}, http.MethodPost)
+	})
+}
+
+// postTask adds a task with the given fields and returns its id.
+func postTask(t *testing.T, values map[string]any) string {
+	ret, err := postJSON("api/task", values, http.MethodPost)
 	assert.NoError(t, err)
 	assert.NotNil(t, ret["id"])
 	id := fmt.Sprint(ret["id"])
@@ -24,6 +29,30 @@ func addTask(t *testing.T, task task) string {
 	return id
 }
 
+func deleteTask(t *testing.T, id string) {
+	ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
+
+// taskTitles returns the titles of the tasks listed by api/tasks with the
+// query, e.g. "?project=inbox", in the order of the list.
+func taskTitles(t *testing.T, query string) []string {
+	body, err := requestJSON("api/tasks"+query, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		Tasks []struct {
+			Title string `json:"title"`
+		} `json:"tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	titles := []string{}
+	for _, task := range resp.Tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
 func getTasks(t *testing.T, search string) []map[string]string {
 	url := "api/tasks"
 	if Search {
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskTime(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	tomorrow := now.AddDate(0, 0, 1).Format(`20060102`)
+
+	id := postTask(t, map[string]any{
+		"date":     tomorrow,
+		"title":    "Позвонить",
+		"time":     "9:30",
+		"duration": 45,
+	})
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var task map[string]any
+	assert.NoError(t, json.Unmarshal(body, &task))
+	assert.Equal(t, "09:30", task["time"])
+	assert.Equal(t, float64(45), task["duration"])
+	deleteTask(t, id)
+
+	_, err = db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+	for _, taskTime := range []string{"18:00", "09:00", ""} {
+		postTask(t, map[string]any{
+			"date":  tomorrow,
+			"title": "Задача " + taskTime,
+			"time":  taskTime,
+		})
+	}
+	tasks := getTasks(t, "")
+	assert.Len(t, tasks, 3)
+	if len(tasks) == 3 {
+		assert.Equal(t, "", tasks[0]["time"])
+		assert.Equal(t, "09:00", tasks[1]["time"])
+		assert.Equal(t, "18:00", tasks[2]["time"])
+	}
+	_, err = db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	// an hourly task that is already due moves to its next occurrence
+	start := now.Add(-time.Hour)
+	id = postTask(t, map[string]any{
+		"date":   start.Format(`20060102`),
+		"time":   start.Format(`15:04`),
+		"title":  "Проветрить",
+		"repeat": "h 3",
+	})
+	var dbTask Task
+	err = db.Get(&dbTask, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	next := start.Add(3 * time.Hour)
+	assert.Equal(t, next.Format(`20060102`), dbTask.Date)
+	assert.Equal(t, next.Format(`15:04`), dbTask.Time)
+
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	err = db.Get(&dbTask, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	next = next.Add(3 * time.Hour)
+	assert.Equal(t, next.Format(`20060102`), dbTask.Date)
+	assert.Equal(t, next.Format(`15:04`), dbTask.Time)
+	deleteTask(t, id)
+
+	body, err = getBody("api/nextdate?now=20240126&date=20240125&repeat=" + url.QueryEscape("h 5"))
+	assert.NoError(t, err)
+	assert.Equal(t, "20240126", string(body))
+
+	body, err = getBody("api/occurrences?now=20240126&date=20240125&count=3&repeat=" + url.QueryEscape("h 5"))
+	assert.NoError(t, err)
+	var dates []string
+	assert.NoError(t, json.Unmarshal(body, &dates))
+	assert.Equal(t, []string{"20240126 01:00", "20240126 06:00", "20240126 11:00"}, dates)
+
+	for _, values := range []map[string]any{
+		{"title": "Неверное время", "time": "25:00"},
+		{"title": "Неверный формат", "time": "9.30"},
+		{"title": "Отрицательно", "time": "09:00", "duration": -1},
+		{"title": "Больше суток", "duration": 24*60 + 1},
+		{"title": "Без времени", "repeat": "h 3"},
+		{"title": "Неверный интервал", "time": "09:00", "repeat": "h 0"},
+	} {
+		values["date"] = tomorrow
+		m, err := postJSON("api/task", values, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := m["error"]
+		assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для задачи %v", values)
+	}
+}
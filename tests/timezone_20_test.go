@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeZone(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	// the dates in these zones always differ as they are 25 hours apart
+	east, err := time.LoadLocation("Pacific/Kiritimati")
+	assert.NoError(t, err)
+	west, err := time.LoadLocation("Pacific/Pago_Pago")
+	assert.NoError(t, err)
+
+	for _, loc := range []*time.Location{east, west} {
+		tomorrow := time.Now().In(loc).AddDate(0, 0, 1).Format(`20060102`)
+
+		body, err := getBody("api/nextdate?date=20240101&repeat=" + url.QueryEscape("d 1") +
+			"&tz=" + url.QueryEscape(loc.String()))
+		assert.NoError(t, err)
+		assert.Equal(t, tomorrow, string(body), "зона %s", loc)
+
+		req, err := http.NewRequest(http.MethodGet, getURL("api/nextdate?date=20240101&repeat="+url.QueryEscape("d 1")), nil)
+		assert.NoError(t, err)
+		req.Header.Set("X-Timezone", loc.String())
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		body, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, tomorrow, string(body), "зона %s", loc)
+	}
+
+	// tomorrow in the west has already come in the east
+	date := time.Now().In(west).AddDate(0, 0, 1).Format(`20060102`)
+	for _, v := range []struct {
+		loc      *time.Location
+		expected string
+	}{
+		{west, date},
+		{east, time.Now().In(east).Format(`20060102`)},
+	} {
+		ret, err := postJSON("api/task?tz="+url.QueryEscape(v.loc.String()), map[string]any{
+			"date":  date,
+			"title": "Созвон",
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotNil(t, ret["id"])
+		id := fmt.Sprint(ret["id"])
+
+		var task Task
+		err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		assert.Equal(t, v.expected, task.Date, "зона %s", v.loc)
+
+		deleteTask(t, id)
+	}
+
+	ret, err := postJSON("api/task?tz=Mars/Olympus", map[string]any{
+		"title": "Созвон",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	e, ok := ret["error"]
+	assert.False(t, !ok || len(fmt.Sprint(e)) == 0, "Ожидается ошибка для неизвестной зоны")
+
+	resp, err := http.Get(getURL("api/nextdate?date=20240101&repeat=d+1&tz=Mars/Olympus"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
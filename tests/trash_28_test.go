@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTrash(t *testing.T) map[string]string {
+	return getTrashIn(t, "")
+}
+
+// getTrashIn lists the trash with the times of deletion in the time zone tz.
+func getTrashIn(t *testing.T, tz string) map[string]string {
+	body, err := requestJSON("api/trash?tz="+tz, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var resp struct {
+		Tasks []struct {
+			ID        string `json:"id"`
+			DeletedAt string `json:"deleted_at"`
+		} `json:"tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &resp))
+	trash := map[string]string{}
+	for _, task := range resp.Tasks {
+		trash[task.ID] = task.DeletedAt
+	}
+	return trash
+}
+
+func TestTrash(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	_, err := db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+
+	now := time.Now()
+	id := postTask(t, map[string]any{
+		"date":  now.Format(`20060102`),
+		"title": "Разобрать почту",
+		"tags":  []string{"почта"},
+	})
+	ret, err := postJSON("api/task/checklist", map[string]any{"task_id": id, "title": "Ответить Ивану"}, http.MethodPost)
+	assert.NoError(t, err)
+	item := fmt.Sprint(ret["id"])
+
+	deleteTask(t, id)
+	notFoundTask(t, id)
+	assert.Empty(t, taskTitles(t, ""))
+	assert.Regexp(t, `^`+now.Format(`20060102`)+` \d\d:\d\d:\d\d$`, getTrash(t)[id])
+	kiritimati, err := time.LoadLocation("Pacific/Kiritimati")
+	assert.NoError(t, err)
+	there := time.Now().In(kiritimati).Format(`20060102`)
+	assert.Regexp(t, `^`+there+` \d\d:\d\d:\d\d$`, getTrashIn(t, "Pacific/Kiritimati")[id])
+
+	// a deleted task cannot be deleted or done again
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	// nor can its checklist be changed
+	ret, err = postJSON("api/task/checklist/check?id="+item, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/task/checklist/uncheck?id="+item, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/task/checklist?id="+item, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, []string{"Разобрать почту"}, taskTitles(t, ""))
+	assert.NotContains(t, getTrash(t), id)
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var restored struct {
+		Tags      []string `json:"tags"`
+		DeletedAt string   `json:"deleted_at"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &restored))
+	assert.Equal(t, []string{"почта"}, restored.Tags)
+	assert.Empty(t, restored.DeletedAt)
+	ret, err = postJSON("api/task/checklist/check?id="+item, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// the tasks of a project deleted with them come back to the inbox
+	ret, err = postJSON("api/project", map[string]any{"name": "Переезд"}, http.MethodPost)
+	assert.NoError(t, err)
+	project := fmt.Sprint(ret["id"])
+	moving := postTask(t, map[string]any{
+		"date":       now.Format(`20060102`),
+		"title":      "Заказать грузчиков",
+		"project_id": project,
+	})
+	ret, err = postJSON("api/project?id="+project+"&mode=cascade", nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Contains(t, getTrash(t), moving)
+	ret, err = postJSON("api/trash/restore?id="+moving, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.ElementsMatch(t, []string{"Заказать грузчиков", "Разобрать почту"}, taskTitles(t, "?project=inbox"))
+
+	deleteTask(t, id)
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.NotContains(t, getTrash(t), id)
+
+	var left int
+	err = db.Get(&left, `SELECT COUNT(*) FROM scheduler WHERE id = ?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, left)
+	err = db.Get(&left, `SELECT COUNT(*) FROM task_tags WHERE task_id = ?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, left)
+
+	if os.Getenv("TODO_DEBUG") != "" && os.Getenv("TODO_TRASH_DAYS") == "" {
+		// a task deleted long ago is purged when the trash is opened
+		body, err := requestAsOf("api/task?id="+moving, nil, http.MethodDelete, "20240101")
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{}`, string(body))
+		assert.NotContains(t, getTrash(t), moving)
+		err = db.Get(&left, `SELECT COUNT(*) FROM scheduler WHERE id = ?`, moving)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, left)
+	}
+
+	_, err = db.Exec("DELETE FROM scheduler")
+	assert.NoError(t, err)
+}